@@ -0,0 +1,263 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ArchiveStubName is left in a month directory whose index/data files were
+// moved to cold storage by RunArchive.
+const ArchiveStubName = "archive.json"
+
+// archiveStub is the JSON content of an ArchiveStubName file.
+type archiveStub struct {
+	Dir        string `json:"dir"` // directory now holding index.quantdev + data.quantdev
+	ArchivedAt string `json:"archivedAt"`
+}
+
+// resolveMonthDir returns the directory that actually holds a month's
+// index.quantdev/data.quantdev. Local files win; otherwise the archive stub
+// (if any) redirects to the cold-storage copy. archived is non-empty when
+// the month has been archived.
+func resolveMonthDir(dir string) (resolved, archived string) {
+	if _, err := os.Stat(filepath.Join(dir, "index.quantdev")); err == nil {
+		return dir, ""
+	}
+	raw, err := os.ReadFile(filepath.Join(dir, ArchiveStubName))
+	if err != nil {
+		return dir, ""
+	}
+	var stub archiveStub
+	if err := json.Unmarshal(raw, &stub); err != nil || stub.Dir == "" {
+		return dir, ""
+	}
+	return stub.Dir, stub.Dir
+}
+
+// RunArchive moves every month that ends before `before` (YYYY-MM-DD) to
+// dest/<SYM>/<YYYY>/<MM>/ and leaves an archive stub behind. Loaders read
+// archived months transparently while dest is mounted.
+func RunArchive(before, dest string) {
+	cutoff, err := time.Parse("2006-01-02", before)
+	if err != nil || dest == "" {
+		fmt.Println("Usage: go run . archive -before YYYY-MM-DD -dest DIR")
+		return
+	}
+
+	fmt.Println(">>> ARCHIVE RAW DATA <<<")
+	fmt.Printf("BaseDir: %s | Before: %s | Dest: %s\n\n", BaseDir, before, dest)
+
+	var moved, bytes int64
+	for _, m := range listMonthDirs("") {
+		// A month is archivable only if its last day is before the cutoff.
+		nextMonth := time.Date(m.Year, time.Month(m.Month)+1, 1, 0, 0, 0, 0, time.UTC)
+		if nextMonth.After(cutoff) {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(m.Dir, "index.quantdev")); err != nil {
+			continue // already archived or empty
+		}
+
+		dstDir := filepath.Join(dest, m.Sym, sprintfYear(m.Year), sprintfMonth(m.Month))
+		n, err := archiveMonth(m.Dir, dstDir)
+		if err != nil {
+			fmt.Printf("  [%s] %04d-%02d  ERROR: %v\n", m.Sym, m.Year, m.Month, err)
+			continue
+		}
+		moved++
+		bytes += n
+		fmt.Printf("  [%s] %04d-%02d  -> %s (%.1f MB)\n", m.Sym, m.Year, m.Month, dstDir, float64(n)/1e6)
+	}
+
+	fmt.Printf("\n[archive] Moved %d months (%.1f MB)\n", moved, float64(bytes)/1e6)
+}
+
+// RunUnarchive restores archived months back into their data root. sym and
+// month (YYYY-MM) are optional filters; empty means all.
+func RunUnarchive(sym, month string) {
+	fmt.Println(">>> UNARCHIVE RAW DATA <<<")
+
+	var restored int64
+	for _, m := range listMonthDirs(sym) {
+		if month != "" && fmt.Sprintf("%04d-%02d", m.Year, m.Month) != month {
+			continue
+		}
+		_, archived := resolveMonthDir(m.Dir)
+		if archived == "" {
+			continue
+		}
+		if err := restoreMonth(archived, m.Dir); err != nil {
+			fmt.Printf("  [%s] %04d-%02d  ERROR: %v\n", m.Sym, m.Year, m.Month, err)
+			continue
+		}
+		restored++
+		fmt.Printf("  [%s] %04d-%02d  <- %s\n", m.Sym, m.Year, m.Month, archived)
+	}
+
+	fmt.Printf("\n[unarchive] Restored %d months\n", restored)
+}
+
+// monthDir is one <SYM>/<YYYY>/<MM> directory under the symbol's root.
+type monthDir struct {
+	Sym         string
+	Year, Month int
+	Dir         string
+}
+
+// listMonthDirs returns all month directories of all roots (optionally for
+// a single symbol), sorted by symbol and date.
+func listMonthDirs(onlySym string) []monthDir {
+	var out []monthDir
+	for sym := range discoverSymbols() {
+		if onlySym != "" && sym != onlySym {
+			continue
+		}
+		root := filepath.Join(SymbolRoot(sym), sym)
+		years, _ := os.ReadDir(root)
+		for _, y := range years {
+			year, err := strconv.Atoi(y.Name())
+			if !y.IsDir() || len(y.Name()) != 4 || err != nil {
+				continue
+			}
+			months, _ := os.ReadDir(filepath.Join(root, y.Name()))
+			for _, m := range months {
+				month, err := strconv.Atoi(m.Name())
+				if !m.IsDir() || len(m.Name()) != 2 || err != nil {
+					continue
+				}
+				out = append(out, monthDir{sym, year, month, filepath.Join(root, y.Name(), m.Name())})
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Sym != out[j].Sym {
+			return out[i].Sym < out[j].Sym
+		}
+		if out[i].Year != out[j].Year {
+			return out[i].Year < out[j].Year
+		}
+		return out[i].Month < out[j].Month
+	})
+	return out
+}
+
+// archiveMonth copies index/data to dstDir, writes the stub, and only then
+// removes the local files, so an interruption never loses the originals.
+func archiveMonth(srcDir, dstDir string) (int64, error) {
+	if err := os.MkdirAll(dstDir, 0o755); err != nil {
+		return 0, err
+	}
+	var total int64
+	for _, name := range []string{"data.quantdev", "index.quantdev"} {
+		n, err := copyFileSync(filepath.Join(srcDir, name), filepath.Join(dstDir, name))
+		if err != nil {
+			return 0, err
+		}
+		total += n
+	}
+
+	// Absolute, so the stub resolves no matter where later runs start.
+	absDir, err := filepath.Abs(dstDir)
+	if err != nil {
+		return 0, err
+	}
+	stub, err := json.MarshalIndent(archiveStub{
+		Dir:        absDir,
+		ArchivedAt: time.Now().UTC().Format(time.RFC3339),
+	}, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	if err := writeFileAtomic(filepath.Join(srcDir, ArchiveStubName), stub); err != nil {
+		return 0, err
+	}
+
+	// Index first: once it is gone, resolveMonthDir follows the stub.
+	if err := os.Remove(filepath.Join(srcDir, "index.quantdev")); err != nil {
+		return 0, err
+	}
+	if err := os.Remove(filepath.Join(srcDir, "data.quantdev")); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// restoreMonth copies the archived files back, then removes the stub and
+// the cold-storage copy.
+func restoreMonth(archDir, dstDir string) error {
+	// Data first: the local index is what makes the month "local" again.
+	for _, name := range []string{"data.quantdev", "index.quantdev"} {
+		if _, err := copyFileSync(filepath.Join(archDir, name), filepath.Join(dstDir, name)); err != nil {
+			return err
+		}
+	}
+	if err := os.Remove(filepath.Join(dstDir, ArchiveStubName)); err != nil {
+		return err
+	}
+	os.Remove(filepath.Join(archDir, "index.quantdev"))
+	os.Remove(filepath.Join(archDir, "data.quantdev"))
+	return nil
+}
+
+// copyFileSync copies src to dst via a temp file + rename, fsyncing before
+// the rename so a crash never leaves a truncated dst, and the directory
+// after it so the rename itself survives one.
+func copyFileSync(src, dst string) (int64, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return 0, err
+	}
+	defer in.Close()
+
+	tmp := dst + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(out, in)
+	if err == nil {
+		err = out.Sync()
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return 0, err
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		return 0, err
+	}
+	return n, syncDir(filepath.Dir(dst))
+}
+
+// writeFileAtomic writes data to path via a temp file + rename, with the
+// same fsyncs as copyFileSync.
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	return syncDir(filepath.Dir(path))
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// benchRows is the trade count of each benchmark fixture day.
+const benchRows = 200_000
+
+func benchFixtureBlob(b *testing.B) []byte {
+	b.Helper()
+	tasks := writeFixture(b, 1, benchRows, 0)
+	var buf []byte
+	if !LoadGNCFile(SymbolRoot(fixtureSym), fixtureSym, tasks[0], &buf) {
+		b.Fatal("LoadGNCFile failed")
+	}
+	return buf
+}
+
+func BenchmarkInflateGNC(b *testing.B) {
+	blob := benchFixtureBlob(b)
+	cols := &DayColumns{}
+	b.SetBytes(int64(len(blob)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := InflateGNC(blob, cols); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRunStream(b *testing.B) {
+	blob := benchFixtureBlob(b)
+	cols := &DayColumns{}
+	if _, err := InflateGNC(blob, cols); err != nil {
+		b.Fatal(err)
+	}
+	models := GetContinuousModels()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		RunStream(cols, models)
+	}
+	b.ReportMetric(float64(benchRows)*float64(b.N)/b.Elapsed().Seconds()/1e6, "Mrows/s")
+}
+
+func BenchmarkAnalyzeFullSuiteOOS(b *testing.B) {
+	oldIters := BootstrapIters
+	BootstrapIters = 100
+	b.Cleanup(func() { BootstrapIters = oldIters })
+
+	tasks := writeFixture(b, 20, 20_000, 0)
+	times, feats, targs := loadFixtureDays(b, tasks, &DayColumns{}, GetContinuousModels())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		AnalyzeFullSuiteOOS(times, feats, targs, 0.7)
+	}
+}
+
+func BenchmarkMergeDayTrades(b *testing.B) {
+	days := mergeFixture(rand.New(rand.NewSource(8)), 1_000_000, 1_000_000, 1_000_000)
+	rows := 0
+	for _, c := range days {
+		rows += c.Count
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		n := 0
+		for range MergeDayTrades(days) {
+			n++
+		}
+		if n != rows {
+			b.Fatalf("merged %d rows, want %d", n, rows)
+		}
+	}
+	b.ReportMetric(float64(rows)*float64(b.N)/b.Elapsed().Seconds()/1e6, "Mrows/s")
+}
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// idxRow is one 26-byte index.quantdev row:
+// Day[2] + Offset[8] + Length[8] + Checksum[8].
+type idxRow struct {
+	Day      int
+	Offset   uint64
+	Length   uint64
+	Checksum [8]byte
+}
+
+// readIndexFile parses an index.quantdev into its raw 16-byte header and rows.
+func readIndexFile(idxPath string) ([16]byte, []idxRow, error) {
+	var hdr [16]byte
+	f, err := os.Open(idxPath)
+	if err != nil {
+		return hdr, nil, err
+	}
+	defer f.Close()
+
+	if _, err := io.ReadFull(f, hdr[:]); err != nil {
+		return hdr, nil, err
+	}
+	if string(hdr[0:4]) != IdxMagic {
+		return hdr, nil, fmt.Errorf("index magic mismatch")
+	}
+	count := binary.LittleEndian.Uint64(hdr[8:16])
+
+	rows := make([]idxRow, 0, count)
+	var row [26]byte
+	for i := uint64(0); i < count; i++ {
+		if _, err := io.ReadFull(f, row[:]); err != nil {
+			return hdr, nil, fmt.Errorf("index truncated at row %d: %w", i, err)
+		}
+		r := idxRow{
+			Day:    int(binary.LittleEndian.Uint16(row[0:2])),
+			Offset: binary.LittleEndian.Uint64(row[2:10]),
+			Length: binary.LittleEndian.Uint64(row[10:18]),
+		}
+		copy(r.Checksum[:], row[18:26])
+		rows = append(rows, r)
+	}
+	return hdr, rows, nil
+}
+
+// encodeIndexFile serializes a header + rows in index.quantdev layout.
+func encodeIndexFile(hdr [16]byte, rows []idxRow) []byte {
+	binary.LittleEndian.PutUint64(hdr[8:16], uint64(len(rows)))
+	out := make([]byte, 0, 16+26*len(rows))
+	out = append(out, hdr[:]...)
+	var row [26]byte
+	for _, r := range rows {
+		binary.LittleEndian.PutUint16(row[0:2], uint16(r.Day))
+		binary.LittleEndian.PutUint64(row[2:10], r.Offset)
+		binary.LittleEndian.PutUint64(row[10:18], r.Length)
+		copy(row[18:26], r.Checksum[:])
+		out = append(out, row[:]...)
+	}
+	return out
+}
+
+// blobChecksum is the index checksum of a blob: the first 8 bytes of its SHA-256.
+func blobChecksum(blob []byte) [8]byte {
+	var c [8]byte
+	sum := sha256.Sum256(blob)
+	copy(c[:], sum[:8])
+	return c
+}
+
+// RunCompact rewrites every local month's data.quantdev so it holds only the
+// blobs referenced by the index, in day order. Superseded generations left
+// behind by repairs are dropped. Each month is swapped atomically and the
+// originals are kept as .bak until the new files verify.
+func RunCompact() {
+	start := time.Now()
+
+	fmt.Println(">>> COMPACT RAW DATA <<<")
+
+	var months int
+	var reclaimed int64
+	for _, m := range listMonthDirs("") {
+		n, err := compactMonth(m.Dir)
+		if err != nil {
+			fmt.Printf("  [%s] %04d-%02d  ERROR: %v\n", m.Sym, m.Year, m.Month, err)
+			continue
+		}
+		if n == 0 {
+			continue
+		}
+		months++
+		reclaimed += n
+		fmt.Printf("  [%s] %04d-%02d  reclaimed %.1f MB\n", m.Sym, m.Year, m.Month, float64(n)/1e6)
+	}
+
+	fmt.Printf("\n[compact] %d months compacted, %.1f MB reclaimed in %s\n", months, float64(reclaimed)/1e6, time.Since(start))
+}
+
+// compactMonth compacts one month directory and returns the bytes reclaimed
+// (0 when the data file was already tight).
+func compactMonth(dir string) (int64, error) {
+	idxPath := filepath.Join(dir, "index.quantdev")
+	dataPath := filepath.Join(dir, "data.quantdev")
+
+	if err := recoverCompaction(dir); err != nil {
+		return 0, err
+	}
+	if _, err := os.Stat(idxPath); err != nil {
+		return 0, nil // archived or empty month
+	}
+
+	hdr, rows, err := readIndexFile(idxPath)
+	if err != nil {
+		return 0, err
+	}
+	fi, err := os.Stat(dataPath)
+	if err != nil {
+		return 0, err
+	}
+	oldSize := fi.Size()
+
+	// Keep the first row per day, matching findBlobOffset's lookup.
+	seen := make(map[int]struct{}, len(rows))
+	kept := rows[:0:0]
+	for _, r := range rows {
+		if _, ok := seen[r.Day]; ok {
+			continue
+		}
+		seen[r.Day] = struct{}{}
+		kept = append(kept, r)
+	}
+	sort.Slice(kept, func(i, j int) bool { return kept[i].Day < kept[j].Day })
+
+	var newSize int64
+	tight := len(kept) == len(rows)
+	for _, r := range kept {
+		if r.Offset != uint64(newSize) {
+			tight = false
+		}
+		newSize += int64(r.Length)
+	}
+	if tight && newSize == oldSize {
+		return 0, nil
+	}
+
+	// Stream referenced blobs into the new data file, verifying as we go.
+	src, err := os.Open(dataPath)
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+
+	newData := dataPath + ".new"
+	dst, err := os.Create(newData)
+	if err != nil {
+		return 0, err
+	}
+	var buf []byte
+	var off uint64
+	newRows := make([]idxRow, len(kept))
+	for i, r := range kept {
+		if cap(buf) < int(r.Length) {
+			buf = make([]byte, r.Length)
+		}
+		buf = buf[:r.Length]
+		if _, err := src.ReadAt(buf, int64(r.Offset)); err != nil {
+			dst.Close()
+			os.Remove(newData)
+			return 0, fmt.Errorf("day %02d: read: %w", r.Day, err)
+		}
+		if blobChecksum(buf) != r.Checksum {
+			dst.Close()
+			os.Remove(newData)
+			return 0, fmt.Errorf("day %02d: checksum mismatch, month left untouched", r.Day)
+		}
+		if _, err := dst.Write(buf); err != nil {
+			dst.Close()
+			os.Remove(newData)
+			return 0, err
+		}
+		newRows[i] = idxRow{Day: r.Day, Offset: off, Length: r.Length, Checksum: r.Checksum}
+		off += r.Length
+	}
+	if err := dst.Sync(); err != nil {
+		dst.Close()
+		os.Remove(newData)
+		return 0, err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(newData)
+		return 0, err
+	}
+	src.Close()
+
+	newIdx := idxPath + ".new"
+	if err := writeFileAtomic(newIdx, encodeIndexFile(hdr, newRows)); err != nil {
+		os.Remove(newData)
+		return 0, err
+	}
+
+	// Swap: originals to .bak, then .new to live. recoverCompaction handles a
+	// crash at any point in between.
+	if err := os.Rename(dataPath, dataPath+".bak"); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(idxPath, idxPath+".bak"); err != nil {
+		os.Rename(dataPath+".bak", dataPath)
+		return 0, err
+	}
+	if err := os.Rename(newData, dataPath); err != nil {
+		return 0, restoreBackups(dir, err)
+	}
+	if err := os.Rename(newIdx, idxPath); err != nil {
+		return 0, restoreBackups(dir, err)
+	}
+	// Persist the renames before dropping the backups, so a crash cannot
+	// leave the directory without either generation.
+	if err := syncDir(dir); err != nil {
+		return 0, restoreBackups(dir, err)
+	}
+
+	if err := verifyMonth(dir); err != nil {
+		return 0, restoreBackups(dir, fmt.Errorf("verify after swap: %w", err))
+	}
+	os.Remove(dataPath + ".bak")
+	os.Remove(idxPath + ".bak")
+	return oldSize - int64(off), nil
+}
+
+// verifyMonth re-reads every indexed blob and checks its checksum.
+func verifyMonth(dir string) error {
+	_, rows, err := readIndexFile(filepath.Join(dir, "index.quantdev"))
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(filepath.Join(dir, "data.quantdev"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var buf []byte
+	for _, r := range rows {
+		if cap(buf) < int(r.Length) {
+			buf = make([]byte, r.Length)
+		}
+		buf = buf[:r.Length]
+		if _, err := f.ReadAt(buf, int64(r.Offset)); err != nil {
+			return fmt.Errorf("day %02d: %w", r.Day, err)
+		}
+		if blobChecksum(buf) != r.Checksum {
+			return fmt.Errorf("day %02d: checksum mismatch", r.Day)
+		}
+	}
+	return nil
+}
+
+// restoreBackups puts the .bak originals back after a failed swap.
+func restoreBackups(dir string, cause error) error {
+	for _, name := range []string{"data.quantdev", "index.quantdev"} {
+		live := filepath.Join(dir, name)
+		if _, err := os.Stat(live + ".bak"); err != nil {
+			continue
+		}
+		os.Remove(live)
+		if err := os.Rename(live+".bak", live); err != nil {
+			return fmt.Errorf("%v; restoring %s failed: %w", cause, name, err)
+		}
+	}
+	return cause
+}
+
+// recoverCompaction finishes or rolls back a compaction interrupted in a
+// previous run, based on which of the live/.bak/.new files survived.
+func recoverCompaction(dir string) error {
+	idxPath := filepath.Join(dir, "index.quantdev")
+	dataPath := filepath.Join(dir, "data.quantdev")
+
+	exists := func(p string) bool {
+		_, err := os.Stat(p)
+		return err == nil
+	}
+
+	if exists(dataPath+".bak") || exists(idxPath+".bak") {
+		// Swap was in progress. Keep the new files only if both are live and verify.
+		if exists(dataPath) && exists(idxPath) && !exists(idxPath+".new") && verifyMonth(dir) == nil {
+			os.Remove(dataPath + ".bak")
+			os.Remove(idxPath + ".bak")
+		} else if err := restoreBackups(dir, nil); err != nil {
+			return err
+		}
+	}
+	// Leftover .new files never made it to live; discard them.
+	os.Remove(dataPath + ".new")
+	os.Remove(idxPath + ".new")
+	os.Remove(idxPath + ".new.tmp")
+	return nil
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"runtime"
 	"sort"
+	"time"
 )
 
 // This is the shared data root produced by the downloader project.
@@ -12,16 +13,219 @@ import (
 //	Z:\DATA\data\ETHUSDT\2020\01\...
 const BaseDir = `Z:\DATA\data`
 
+// SymbolRoots routes individual symbols to a data root other than BaseDir,
+// e.g. BTCUSDT on fast NVMe and alts on a slower external disk. Each root
+// has the same <SYM>/<YYYY>/<MM> layout as BaseDir.
+//
+//	"BTCUSDT": `D:\nvme\data`,
+var SymbolRoots = map[string]string{}
+
+// SymbolRoot returns the data root that holds sym.
+func SymbolRoot(sym string) string {
+	if root, ok := SymbolRoots[sym]; ok && root != "" {
+		return root
+	}
+	return BaseDir
+}
+
 // SamplingRateSec: How often we "snapshot" the continuous physics.
 const SamplingRateSec = 60
 
-// Horizon definitions for the regression targets.
-var HorizonLabels = []string{"15m", "30m", "1h"}
-var HorizonDelays = []int64{
-	15 * 60 * 1000, // 15 min in ms
-	30 * 60 * 1000, // 30 min in ms
-	60 * 60 * 1000, // 60 min in ms
-}
+// Horizon definitions for the regression targets. Units: ms/s/m/h are wall
+// clock, t is a number of trades, $ is traded notional (see ParseHorizon).
+// Override with e.g. `test -horizons 15m,30m,1h,2M$,10M$,50M$`.
+var Horizons = mustParseHorizons("15m,30m,1h")
+var HorizonLabels = horizonLabels(Horizons)
+
+// ReturnDef selects the forward-return definition used for targets:
+//
+//	"log"    - log(p_exit / p_entry) on last-trade prints (default)
+//	"simple" - p_exit / p_entry - 1
+//	"vwap"   - log ratio of the VWAPs of the VWAPTrades trades starting at
+//	           entry and of those ending at exit; smooths out single-print
+//	           noise without reading past the horizon
+var ReturnDef = "log"
+
+// VWAPTrades is the number of trades averaged at each endpoint for "vwap".
+var VWAPTrades = 20
+
+// DaysPerYear annualizes Sharpe ratios of daily PnL: AnnualizedSharpe =
+// daily mean / daily std * sqrt(DaysPerYear). Per-sample Sharpes of
+// overlapping forward returns cannot be annualized by the sampling rate;
+// a day's summed PnL is one non-overlapping period at every horizon. The
+// default fits a 24/7 market; pass 252 for exchange calendars.
+var DaysPerYear = 365.0
+
+// TrainFrac is the chronological fraction of samples in the train (IS)
+// segment; the rest is the test (OOS) segment. ml-export splits its days at
+// the same boundary.
+var TrainFrac = 0.7
+
+// BootstrapIters and BootstrapBlockDays control the day-block bootstrap of
+// OOS IC and breakeven cost: each iteration resamples blocks of consecutive
+// test days with replacement.
+var (
+	BootstrapIters     = 1000
+	BootstrapBlockDays = 1
+)
+
+// SignalDir, when set, adds an external signal loaded from CSV files
+// (ts_ms,signal) as an extra model; see LoadSignalSeries. SignalAlign is
+// "ffill" or "nearest".
+var (
+	SignalDir   = ""
+	SignalAlign = "ffill"
+)
+
+// LogPath, when set, tees all report tables into one plain-text log file
+// headed by the run's timestamp and command line.
+var LogPath = ""
+
+// UseLedger records every OOS evaluation in LedgerPath and warns about
+// models whose test segment has already been looked at. NoOOS withholds the
+// test segment entirely (in-sample research mode).
+var (
+	UseLedger = false
+	NoOOS     = false
+)
+
+// ExportCSVPath, when set, writes one CSV row of stats per (symbol, model,
+// horizon, day) for analysis in external tools.
+var ExportCSVPath = ""
+
+// TimingCSVPath, when set, writes one CSV row of load/stream wall time and
+// throughput per (symbol, day).
+var TimingCSVPath = ""
+
+// Verbose adds the detailed per-trade sections to the report.
+var Verbose = false
+
+// MakerSim enables the maker-fill simulation: a limit order at the sample
+// price fills only if a later trade prints at or through it within
+// MakerFillSec. MakerFeeBps is charged per fill (negative = rebate).
+var (
+	MakerSim     = false
+	MakerFillSec = 30
+	MakerFeeBps  = 0.0
+	// MakerThreshold (train-std units, 0 = off) adds the fill rate of a
+	// threshold strategy that only quotes when |signal - train mean| exceeds it.
+	MakerThreshold = 0.0
+)
+
+// Square-root impact model: cost_bps = ImpactCoefBps *
+// sqrt(ImpactNotional / dollar volume over the last ImpactWindowSec),
+// charged per position change. ImpactCoefBps = 0 disables it.
+var (
+	ImpactCoefBps   = 0.0
+	ImpactNotional  = 10000.0
+	ImpactWindowSec = 60
+)
+
+// WinsorPct > 0 adds a winsorized-IC table: test returns are clipped at the
+// WinsorPct and 1-WinsorPct percentiles of the train returns.
+var WinsorPct = 0.0
+
+// PortfolioSpec ("MODEL@HORIZON", empty = off) aggregates that cell's
+// signal-as-position PnL across all symbols into PortfolioPath.
+var PortfolioSpec = ""
+
+// CrossSection (-xs) adds a per-timestamp rank IC across symbols, written to
+// CrossSectionPath; stamps with fewer than CrossSectionMinSyms symbols are
+// skipped.
+var (
+	CrossSection        = false
+	CrossSectionMinSyms = 3
+)
+
+// Signal-as-position limits, in units of the signal's train-segment std:
+// PositionCap clips |pos|, PositionMaxChange limits |Δpos| per sample.
+// Zero disables each; the section is reported when either is set.
+var (
+	PositionCap       = 0.0
+	PositionMaxChange = 0.0
+)
+
+// MinRowsPerDay (-min-rows) is the trade count below which a day (listing
+// day, exchange outage) is too short to study. test, diag and ml-export skip
+// such days and list them apart from days that failed to load; ml-export
+// -keep-short still writes them, flagged in the file name. 0 disables.
+var MinRowsPerDay = 1000
+
+// VerifyChecksums makes LoadGNCFile hash every blob and skip days whose
+// sha256 prefix does not match the index row (-verify). Off by default:
+// it costs one hash pass per day.
+var VerifyChecksums = false
+
+// HTMLDashboard (-html) also writes Continuous_Algo_Dashboard_<SYM>.html.
+var HTMLDashboard = false
+
+// ImbalanceLadder adds one Imb_L<n> model per window length, in trades
+// (-imb-ladder 1,4,16,64). Empty = off.
+var ImbalanceLadder []int
+
+// SpreadProxyModel (-spread-proxy) adds Spread_Proxy, the trade-implied
+// spread from buyer- vs seller-initiated EWMA prices.
+var SpreadProxyModel = false
+
+// SizeAnomalyModel (-size-anomaly) adds Size_Anomaly, the side-signed
+// z-score of sqrt trade size (and its _Dollar copy with -dollar).
+var SizeAnomalyModel = false
+
+// SignRunModels (-sign-run) adds the Sign_RunLen, Sign_RevRate and
+// Sign_RunZ models of the trade-sign process.
+var SignRunModels = false
+
+// ElasticityAsymModel (-elast-asym) adds Elast_Buy and Elast_Sell, the
+// price move per unit of aggressor buy and sell flow, and their difference
+// Elast_Asym (and their _Dollar copies with -dollar).
+var ElasticityAsymModel = false
+
+// DollarVolume (-dollar) adds a <Model>_Dollar copy of every volume-driven
+// model, fed quote notional (price * qty) instead of base quantity, so the
+// report compares both weightings side by side.
+var DollarVolume = false
+
+// OOSDates (-oos-dates) appends one "[OOS from YYYY-MM-DD]" core summary
+// per date to each report, with the test segment starting at that date
+// instead of after the first 70% of samples. With -ledger each date is
+// recorded as its own OOS evaluation.
+var OOSDates []time.Time
+
+// Guards on the EW-variance z-scores (Size_Anomaly, Imb_L<n>, Sign_RunZ):
+// the variance is floored at ZVarFloor times its long-run estimate and
+// |z| is capped at ZClamp (0 disables the cap). Clamp counts are reported.
+var (
+	ZVarFloor = 0.05
+	ZClamp    = 8.0
+)
+
+// Weights of the top-table composite score: OOS breakeven (bps), effective
+// IC t-stat and the OOS/IS IC ratio at each model's best horizon.
+var (
+	RankWeightBE    = 1.0
+	RankWeightICT   = 0.5
+	RankWeightRatio = 1.0
+)
+
+// TopTableRows is the number of models printed in the report's top table;
+// the JSON summary always carries the full ranking.
+var TopTableRows = 20
+
+// FeatureStats (-feature-stats) adds a per-model table of feature mean,
+// std, non-zero share and NaN/Inf share to the top of each report.
+var FeatureStats = false
+
+// PlanOnly (-plan) prints each symbol's cost estimate and skips the run.
+var PlanOnly = false
+
+// DryRun (-dry-run on test, ml-export and daystats) lists what a run would
+// read and write, then stops before any file is created.
+var DryRun = false
+
+// TrackMAE enables the path scan between entry and exit used for maximum
+// adverse excursion stats. It roughly doubles the labeling cost, so it is
+// off by default (enable with `test -mae`).
+var TrackMAE = false
 
 // System tuning for Ryzen 9 7900X (leave 2 cores free for OS/other work).
 var CPUThreads = func() int {
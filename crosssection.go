@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// CrossSectionPath is where -xs writes its table.
+const CrossSectionPath = "Continuous_Algo_CrossSection.txt"
+
+// xsBook collects every symbol's sampled (signal, forward return) pairs per
+// (model, horizon) cell for the cross-sectional study (-xs). Samples are
+// bucketed onto the SamplingRateSec grid; a later sample in the same bucket
+// replaces an earlier one, so each symbol holds its latest signal there.
+type xsBook struct {
+	Symbols   []string
+	Cells     map[string][]xsPoint // key: model + "\x00" + horizon
+	Models    []string             // first-seen order, for the report
+	TrainFrac float64              // the run's train share, for the IS/OOS stamp split
+}
+
+type xsPoint struct {
+	Bucket int64
+	Sym    int
+	Feat   float64
+	Ret    float64
+}
+
+func newXSBook() *xsBook {
+	return &xsBook{Cells: make(map[string][]xsPoint)}
+}
+
+// AddSymbol records sym's sorted containers.
+func (b *xsBook) AddSymbol(sym string, modelNames []string, results [][]*ResultContainer, trainFrac float64) {
+	b.TrainFrac = trainFrac
+	symIdx := len(b.Symbols)
+	b.Symbols = append(b.Symbols, sym)
+	grid := float64(SamplingRateSec * 1000)
+
+	for mIdx, name := range modelNames {
+		if _, seen := b.Cells[name+"\x00"+HorizonLabels[0]]; !seen {
+			b.Models = append(b.Models, name)
+		}
+		for hIdx, hName := range HorizonLabels {
+			key := name + "\x00" + hName
+			rc := results[hIdx][mIdx]
+			pts := b.Cells[key]
+			for i, t := range rc.Times {
+				p := xsPoint{Bucket: int64(t / grid), Sym: symIdx, Feat: rc.Feats[i], Ret: rc.Targs[i]}
+				if n := len(pts); n > 0 && pts[n-1].Sym == symIdx && pts[n-1].Bucket == p.Bucket {
+					pts[n-1] = p
+					continue
+				}
+				pts = append(pts, p)
+			}
+			b.Cells[key] = pts
+		}
+	}
+}
+
+// xsSegment aggregates per-timestamp rank ICs.
+type xsSegment struct {
+	Stamps int
+	MeanIC float64
+	T      float64 // mean / SE over timestamps (ignores overlap)
+	Days   int
+	DayT   float64 // mean / SE of daily mean ICs
+}
+
+func xsAggregate(buckets []int64, ics []float64) xsSegment {
+	s := xsSegment{Stamps: len(ics)}
+	if len(ics) == 0 {
+		return s
+	}
+	s.MeanIC, s.T = meanTStat(ics)
+
+	grid := int64(SamplingRateSec * 1000)
+	var daily []float64
+	var sum float64
+	var cnt int
+	for i := range ics {
+		sum += ics[i]
+		cnt++
+		if i == len(ics)-1 || buckets[i+1]*grid/dayMillis != buckets[i]*grid/dayMillis {
+			daily = append(daily, sum/float64(cnt))
+			sum, cnt = 0, 0
+		}
+	}
+	s.Days = len(daily)
+	_, s.DayT = meanTStat(daily)
+	return s
+}
+
+// meanTStat returns the mean of xs and its t-stat against zero.
+func meanTStat(xs []float64) (mean, t float64) {
+	for _, x := range xs {
+		mean += x
+	}
+	mean /= float64(len(xs))
+	if sd := stdDev(xs); sd > 0 {
+		t = mean / (sd / math.Sqrt(float64(len(xs))))
+	}
+	return mean, t
+}
+
+// WriteReport computes the Spearman IC across symbols at every grid
+// timestamp with at least minSyms symbols, then splits the timestamps
+// TrainFrac / 1-TrainFrac into IS and OOS. Under -no-oos the containers
+// hold the train segment only, so OOS is the tail of it.
+func (b *xsBook) WriteReport(path string, minSyms int) error {
+	if len(b.Symbols) < minSyms {
+		return fmt.Errorf("cross-sectional IC needs at least %d symbols, have %d", minSyms, len(b.Symbols))
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(f, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "# Cross-sectional rank IC | Symbols: %d | Grid: %ds | Min symbols per stamp: %d | Returns: %s | %s\n",
+		len(b.Symbols), SamplingRateSec, minSyms, returnDefLabel(), time.Now().UTC().Format(time.RFC3339))
+	fmt.Fprintf(w, "# Per grid stamp: Spearman of signal vs forward return across symbols; IS = earliest %.0f%% of stamps; T = mean/SE over stamps (overlapping), DayT = mean/SE of daily means\n", 100*b.TrainFrac)
+	if NoOOS {
+		fmt.Fprintf(w, "# IS-ONLY (-no-oos): test segment withheld; OOS columns use the last %.0f%% of the train segment\n", 100*(1-b.TrainFrac))
+	}
+	fmt.Fprintf(w, "MODEL\tHORIZON\tIS_Stamps\tIS_IC\tIS_T\tIS_DayT\tOOS_Stamps\tOOS_IC\tOOS_T\tOOS_DayT\n")
+	fmt.Fprintf(w, "-----\t-------\t---------\t-----\t----\t-------\t----------\t------\t-----\t--------\n")
+
+	for _, name := range b.Models {
+		for _, hName := range HorizonLabels {
+			pts := b.Cells[name+"\x00"+hName]
+			sort.SliceStable(pts, func(i, j int) bool { return pts[i].Bucket < pts[j].Bucket })
+
+			var buckets []int64
+			var ics []float64
+			var fs, rs []float64
+			for i := 0; i < len(pts); {
+				j := i
+				fs, rs = fs[:0], rs[:0]
+				for ; j < len(pts) && pts[j].Bucket == pts[i].Bucket; j++ {
+					fs = append(fs, pts[j].Feat)
+					rs = append(rs, pts[j].Ret)
+				}
+				if j-i >= minSyms {
+					if ic := Spearman(fs, rs); !math.IsNaN(ic) {
+						buckets = append(buckets, pts[i].Bucket)
+						ics = append(ics, ic)
+					}
+				}
+				i = j
+			}
+
+			split := trainCount(len(ics), b.TrainFrac)
+			is := xsAggregate(buckets[:split], ics[:split])
+			oos := xsAggregate(buckets[split:], ics[split:])
+			fmt.Fprintf(w, "%s\t%s\t%d\t%.4f\t%.2f\t%.2f\t%d\t%.4f\t%.2f\t%.2f\n",
+				name, hName, is.Stamps, is.MeanIC, is.T, is.DayT, oos.Stamps, oos.MeanIC, oos.T, oos.DayT)
+		}
+		fmt.Fprintf(w, "\n")
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"html/template"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+type dashboardData struct {
+	Symbol, Generated, Returns, Horizons string
+	TrainFrac                            float64
+	InSampleOnly                         bool
+	Rows                                 []dashboardRow
+	Daily                                []dashboardDaily
+	Split                                []dashboardSplit
+}
+
+type dashboardRow struct {
+	Model, Horizon                      string
+	TestN                               int
+	PearsonIC, SpearmanIC, ICT, HitRate float64
+	AnnSharpe, PSR, BreakevenBps        float64
+}
+
+type dashboardDaily struct {
+	Model, Horizon string
+	Days           int
+	PosPct         float64
+	Min, Max       float64
+	Spark          string
+}
+
+type dashboardSplit struct {
+	Model, Horizon     string
+	ISN, OOSN          int
+	ISIC, OOSIC, Decay float64
+}
+
+var dashboardTmpl = template.Must(template.New("dashboard").Funcs(template.FuncMap{
+	"sign": func(v float64) string {
+		switch {
+		case v > 0:
+			return "pos"
+		case v < 0:
+			return "neg"
+		}
+		return ""
+	},
+}).Parse(dashboardTemplate))
+
+// writeDashboard renders the symbol's HTML dashboard (-html) from the same
+// sorted containers and core stats as the text report.
+func writeDashboard(path, sym string, modelNames []string, allStats [][]ReportStats, results [][]*ResultContainer, trainFrac float64) error {
+	d := dashboardData{
+		Symbol:       sym,
+		Generated:    time.Now().UTC().Format(time.RFC3339),
+		Returns:      returnDefLabel(),
+		Horizons:     strings.Join(HorizonLabels, ", "),
+		TrainFrac:    trainFrac,
+		InSampleOnly: NoOOS,
+	}
+	for mIdx, name := range modelNames {
+		for hIdx, hName := range HorizonLabels {
+			st := allStats[mIdx][hIdx]
+			if st.TestCount == 0 {
+				continue
+			}
+			d.Rows = append(d.Rows, dashboardRow{
+				Model: name, Horizon: hName, TestN: st.TestCount,
+				PearsonIC: st.PearsonIC, SpearmanIC: st.SpearmanIC, ICT: st.ICTEff, HitRate: st.HitRate,
+				AnnSharpe: st.AnnualizedSharpe, PSR: st.PSR, BreakevenBps: st.BreakevenBps,
+			})
+
+			data := results[hIdx][mIdx]
+			daily := DailyICOOS(data.Times, data.Feats, data.Targs, trainFrac)
+			if len(daily) > 0 {
+				days := make([]int64, 0, len(daily))
+				for day := range daily {
+					days = append(days, day)
+				}
+				sort.Slice(days, func(i, j int) bool { return days[i] < days[j] })
+				ics := make([]float64, len(days))
+				row := dashboardDaily{Model: name, Horizon: hName, Days: len(days), Min: math.Inf(1), Max: math.Inf(-1)}
+				for i, day := range days {
+					ics[i] = daily[day]
+					row.Min = math.Min(row.Min, ics[i])
+					row.Max = math.Max(row.Max, ics[i])
+					if ics[i] > 0 {
+						row.PosPct++
+					}
+				}
+				row.PosPct *= 100 / float64(len(days))
+				row.Spark = sparkline(ics)
+				d.Daily = append(d.Daily, row)
+			}
+
+			s := splitTrainTest(data.Times, data.Feats, data.Targs, trainFrac)
+			isIC, oosIC := Pearson(s.TrainF, s.TrainR), Pearson(s.TestF, s.TestR)
+			d.Split = append(d.Split, dashboardSplit{
+				Model: name, Horizon: hName,
+				ISN: len(s.TrainF), OOSN: len(s.TestF),
+				ISIC: isIC, OOSIC: oosIC, Decay: oosIC - isIC,
+			})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := dashboardTmpl.Execute(&buf, d); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, buf.Bytes())
+}
+
+// sparkline renders vals as one line of Unicode block characters scaled
+// between their min and max.
+func sparkline(vals []float64) string {
+	const ticks = "▁▂▃▄▅▆▇█"
+	levels := []rune(ticks)
+	if len(vals) == 0 {
+		return ""
+	}
+	lo, hi := vals[0], vals[0]
+	for _, v := range vals {
+		lo = math.Min(lo, v)
+		hi = math.Max(hi, v)
+	}
+	var sb strings.Builder
+	for _, v := range vals {
+		k := 0
+		if hi > lo {
+			k = int(math.Round((v - lo) / (hi - lo) * float64(len(levels)-1)))
+		}
+		sb.WriteRune(levels[k])
+	}
+	return sb.String()
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// DayStatsName is the per-month sidecar holding precomputed raw-day
+// statistics, so later passes need not decode the blobs again. It lives in
+// the local month directory, next to the data or the archive stub.
+const DayStatsName = "daystats.json"
+
+// dayStats are the raw-day scalars of one stored day. Checksum is the
+// index checksum of the blob they were computed from; a mismatch means the
+// day was repaired since and the entry is stale.
+type dayStats struct {
+	Checksum     string  `json:"checksum"`
+	Rows         int     `json:"rows"`
+	DollarVolume float64 `json:"dollarVolume"` // sum of price*qty
+	BuyRatio     float64 `json:"buyRatio"`     // taker-buy share of qty
+	First        float64 `json:"first"`
+	High         float64 `json:"high"`
+	Low          float64 `json:"low"`
+	Last         float64 `json:"last"`
+	MaxGapMs     int64   `json:"maxGapMs"`    // longest pause between trades
+	RealizedVol  float64 `json:"realizedVol"` // sqrt of summed squared log returns of 1-minute closes
+}
+
+// computeDayStats derives the sidecar entry of one decoded day.
+func computeDayStats(tb *TradeBlock) dayStats {
+	s := dayStats{Rows: tb.Count}
+	if tb.Count == 0 {
+		return s
+	}
+	s.First, s.Last = tb.Prices[0], tb.Prices[tb.Count-1]
+	s.High, s.Low = s.First, s.First
+
+	var qty, buyQty, rv float64
+	minute, prevClose, lastClose := tb.Times[0]/60000, 0.0, tb.Prices[0]
+	for i := 0; i < tb.Count; i++ {
+		p, q, t := tb.Prices[i], tb.Quantities[i], tb.Times[i]
+		s.DollarVolume += p * q
+		qty += q
+		if !tb.IsBuyerMaker(i) {
+			buyQty += q
+		}
+		s.High = math.Max(s.High, p)
+		s.Low = math.Min(s.Low, p)
+		if i > 0 && t-tb.Times[i-1] > s.MaxGapMs {
+			s.MaxGapMs = t - tb.Times[i-1]
+		}
+		if m := t / 60000; m != minute {
+			if prevClose > 0 && lastClose > 0 {
+				r := math.Log(lastClose / prevClose)
+				rv += r * r
+			}
+			prevClose, minute = lastClose, m
+		}
+		lastClose = p
+	}
+	if prevClose > 0 && lastClose > 0 {
+		r := math.Log(lastClose / prevClose)
+		rv += r * r
+	}
+	s.RealizedVol = math.Sqrt(rv)
+	if qty > 0 {
+		s.BuyRatio = buyQty / qty
+	}
+	return s
+}
+
+// loadDayStats reads a month's sidecar, keyed by day of month. A missing
+// sidecar is not an error and yields an empty map.
+func loadDayStats(monthDir string) (map[int]dayStats, error) {
+	raw, err := os.ReadFile(filepath.Join(monthDir, DayStatsName))
+	if os.IsNotExist(err) {
+		return map[int]dayStats{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var byKey map[string]dayStats
+	if err := json.Unmarshal(raw, &byKey); err != nil {
+		return nil, fmt.Errorf("%s: %v", DayStatsName, err)
+	}
+	out := make(map[int]dayStats, len(byKey))
+	for k, v := range byKey {
+		d, err := strconv.Atoi(k)
+		if err != nil {
+			return nil, fmt.Errorf("%s: bad day key %q", DayStatsName, k)
+		}
+		out[d] = v
+	}
+	return out, nil
+}
+
+// freshDayStats returns sym's up-to-date sidecar entries by date. Days
+// that are missing from their sidecar or stale are absent.
+func freshDayStats(sym string) map[time.Time]dayStats {
+	out := make(map[time.Time]dayStats)
+	for _, m := range listMonthDirs(sym) {
+		p, err := planDayStats(m)
+		if err != nil {
+			continue
+		}
+		for key, s := range p.keep {
+			d, _ := strconv.Atoi(key)
+			out[time.Date(m.Year, time.Month(m.Month), d, 0, 0, 0, 0, time.UTC)] = s
+		}
+	}
+	return out
+}
+
+// RunDayStats brings every month's sidecar up to date: days that are new
+// or whose index checksum changed are decoded and recomputed, entries of
+// days no longer indexed are dropped. Up-to-date months are not rewritten.
+// With DryRun it only lists the stale days and why.
+func RunDayStats(onlySym string) {
+	start := time.Now()
+	fmt.Println(">>> PRECOMPUTE DAY STATS <<<")
+	fmt.Printf("BaseDir: %s\n\n", BaseDir)
+
+	var months, computed int
+	for _, m := range listMonthDirs(onlySym) {
+		if DryRun {
+			p, err := planDayStats(m)
+			if err != nil {
+				fmt.Printf("  [%s] %04d-%02d  ERROR: %v\n", m.Sym, m.Year, m.Month, err)
+				continue
+			}
+			for _, r := range p.stale {
+				fmt.Printf("  [%s] %04d-%02d-%02d  would compute (%s)\n", m.Sym, m.Year, m.Month, r.row.Day, r.reason)
+			}
+			if len(p.dropped) > 0 {
+				fmt.Printf("  [%s] %04d-%02d  would drop %d days no longer indexed\n", m.Sym, m.Year, m.Month, len(p.dropped))
+			}
+			if len(p.stale) > 0 {
+				months++
+				computed += len(p.stale)
+			}
+			continue
+		}
+		n, err := updateDayStats(m)
+		if err != nil {
+			fmt.Printf("  [%s] %04d-%02d  ERROR: %v\n", m.Sym, m.Year, m.Month, err)
+			continue
+		}
+		if n > 0 {
+			months++
+			computed += n
+			fmt.Printf("  [%s] %04d-%02d  %d days computed\n", m.Sym, m.Year, m.Month, n)
+		}
+	}
+	verb := "computed"
+	if DryRun {
+		verb = "to compute (dry run)"
+	}
+	fmt.Printf("\n[daystats] %d days %s in %d months (%s)\n", computed, verb, months, time.Since(start))
+}
+
+// staleDay is an index row whose sidecar entry must be (re)computed.
+type staleDay struct {
+	row    idxRow
+	reason string // "new" or "checksum changed"
+}
+
+// dayStatsPlan is what updateDayStats will do to one month's sidecar.
+type dayStatsPlan struct {
+	dir     string              // directory holding index/data (archive aware)
+	keep    map[string]dayStats // up-to-date entries, by sidecar key
+	stale   []staleDay
+	dropped []int // days in the sidecar but no longer indexed
+}
+
+// planDayStats compares a month's index against its sidecar without
+// decoding any blob.
+func planDayStats(m monthDir) (dayStatsPlan, error) {
+	p := dayStatsPlan{keep: map[string]dayStats{}}
+	p.dir, _ = resolveMonthDir(m.Dir)
+	_, rows, err := readIndexFile(filepath.Join(p.dir, "index.quantdev"))
+	if os.IsNotExist(err) {
+		return p, nil // empty month directory
+	}
+	if err != nil {
+		return p, err
+	}
+	old, err := loadDayStats(m.Dir)
+	if err != nil {
+		return p, err
+	}
+
+	seen := make(map[int]bool, len(rows))
+	for _, r := range rows {
+		if seen[r.Day] {
+			continue // superseded repair row; the first one is live (findBlobOffset)
+		}
+		seen[r.Day] = true
+		s, ok := old[r.Day]
+		switch {
+		case !ok:
+			p.stale = append(p.stale, staleDay{r, "new"})
+		case s.Checksum != hex.EncodeToString(r.Checksum[:]):
+			p.stale = append(p.stale, staleDay{r, "checksum changed"})
+		default:
+			p.keep[sprintf2(r.Day)] = s
+		}
+	}
+	for d := range old {
+		if !seen[d] {
+			p.dropped = append(p.dropped, d)
+		}
+	}
+	return p, nil
+}
+
+// updateDayStats applies planDayStats to one month's sidecar and returns
+// how many days it had to decode.
+func updateDayStats(m monthDir) (int, error) {
+	p, err := planDayStats(m)
+	if err != nil {
+		return 0, err
+	}
+	if len(p.stale) == 0 && len(p.dropped) == 0 {
+		return 0, nil
+	}
+
+	f, err := os.Open(filepath.Join(p.dir, "data.quantdev"))
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	next := p.keep
+	var buf []byte
+	computed := 0
+	for _, sd := range p.stale {
+		r, key := sd.row, sprintf2(sd.row.Day)
+		if uint64(cap(buf)) < r.Length {
+			buf = make([]byte, r.Length)
+		}
+		buf = buf[:r.Length]
+		if _, err := f.ReadAt(buf, int64(r.Offset)); err != nil {
+			return computed, fmt.Errorf("day %s: %v", key, err)
+		}
+		tb, err := mapTradeBlock(buf)
+		if err != nil {
+			return computed, fmt.Errorf("day %s: %v", key, err)
+		}
+		s := computeDayStats(tb)
+		s.Checksum = hex.EncodeToString(r.Checksum[:])
+		next[key] = s
+		computed++
+	}
+
+	raw, err := json.MarshalIndent(next, "", "  ")
+	if err != nil {
+		return computed, err
+	}
+	return computed, writeFileAtomic(filepath.Join(m.Dir, DayStatsName), raw)
+}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// dayDiag holds the raw order-flow diagnostics of one day.
+type dayDiag struct {
+	Year, Month, Day int
+	Trades           int
+	BuyVolFrac       float64   // taker-buy volume / total volume
+	Hurst            float64   // scaling exponent of signed volume (aggregated variance)
+	SignACF          []float64 // [lag-1] autocorrelation of aggressor trade signs
+}
+
+// hurstScales are the block sizes (in trades) for the aggregated-variance fit.
+var hurstScales = []int{8, 16, 32, 64, 128, 256, 512, 1024}
+
+// RunDiag computes per-day order-flow diagnostics straight from the raw
+// trades (no models): trade-sign ACF up to maxLag, a Hurst-style exponent of
+// signed volume and the aggressive-buy volume share. Trade side is the
+// aggressor from the blob's buyer-maker bit. Days with an up-to-date
+// daystats sidecar entry take their trade count and buy share from it, and
+// short days are skipped without decoding. One CSV per symbol
+// (diag_<SYMBOL>.csv) plus monthly averages on stdout.
+func RunDiag(onlySym string, maxLag int) {
+	start := time.Now()
+	if maxLag < 1 {
+		maxLag = 50
+	}
+
+	fmt.Println(">>> ORDER-FLOW DIAGNOSTICS <<<")
+	fmt.Printf("BaseDir: %s | Lags: %d | Workers: %d\n\n", BaseDir, maxLag, CPUThreads)
+
+	var symbols []string
+	for sym := range discoverSymbols() {
+		if onlySym == "" || sym == onlySym {
+			symbols = append(symbols, sym)
+		}
+	}
+	if len(symbols) == 0 {
+		fmt.Println("No symbols discovered under BaseDir.")
+		return
+	}
+	sort.Strings(symbols)
+
+	for _, sym := range symbols {
+		days := diagSymbol(sym, maxLag)
+		if len(days) == 0 {
+			fmt.Printf("[%s] no readable days\n\n", sym)
+			continue
+		}
+		path := fmt.Sprintf("diag_%s.csv", sym)
+		if err := writeDiagCSV(path, days, maxLag); err != nil {
+			fmt.Printf("[%s] ERROR: %v\n", sym, err)
+		}
+		printDiagMonthly(sym, days, maxLag)
+		fmt.Printf("[%s] %d days -> %s\n\n", sym, len(days), path)
+	}
+	fmt.Printf("[diag] done in %s\n", time.Since(start))
+}
+
+// diagSymbol runs dayDiagnostics over every day of sym on the worker pool
+// and returns the results in date order.
+func diagSymbol(sym string, maxLag int) []dayDiag {
+	var tasks []ofiTask
+	for t := range discoverTasks(sym) {
+		tasks = append(tasks, t)
+	}
+
+	taskCh := make(chan ofiTask, len(tasks))
+	for _, t := range tasks {
+		taskCh <- t
+	}
+	close(taskCh)
+	stats := freshDayStats(sym)
+
+	var mu sync.Mutex
+	var out []dayDiag
+	var failed int
+	var short []skippedDay
+	var wg sync.WaitGroup
+	for w := 0; w < CPUThreads; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cols := DayColumnPool.Get().(*DayColumns)
+			defer DayColumnPool.Put(cols)
+			var buf []byte
+			for task := range taskCh {
+				s, haveStats := stats[taskDate(task)]
+				if haveStats && (isShortDay(s.Rows) || s.Rows < 2*maxLag) {
+					mu.Lock()
+					short = append(short, skippedDay{taskDate(task), s.Rows})
+					mu.Unlock()
+					continue
+				}
+				if !LoadGNCFile(SymbolRoot(sym), sym, task, &buf) {
+					mu.Lock()
+					failed++
+					mu.Unlock()
+					continue
+				}
+				if _, err := InflateGNC(buf, cols); err != nil {
+					mu.Lock()
+					failed++
+					mu.Unlock()
+					continue
+				}
+				if isShortDay(cols.Count) || cols.Count < 2*maxLag {
+					mu.Lock()
+					short = append(short, skippedDay{taskDate(task), cols.Count})
+					mu.Unlock()
+					continue
+				}
+				d := dayDiagnostics(cols, maxLag)
+				d.Year, d.Month, d.Day = task.Year, task.Month, task.Day
+				if haveStats {
+					d.Trades, d.BuyVolFrac = s.Rows, s.BuyRatio
+				}
+				mu.Lock()
+				out = append(out, d)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	printSkippedDays(sym, failed, short)
+
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.Year != b.Year {
+			return a.Year < b.Year
+		}
+		if a.Month != b.Month {
+			return a.Month < b.Month
+		}
+		return a.Day < b.Day
+	})
+	return out
+}
+
+// dayDiagnostics makes a single pass over the day. A ring buffer of the last
+// maxLag signs feeds all lagged products at once, and one running block sum
+// per scale feeds the aggregated-variance Hurst fit.
+func dayDiagnostics(cols *DayColumns, maxLag int) dayDiag {
+	n := cols.Count
+	ring := make([]float64, maxLag)
+	lagSum := make([]float64, maxLag)
+	lagCnt := make([]int, maxLag)
+
+	blockSum := make([]float64, len(hurstScales))
+	blockLen := make([]int, len(hurstScales))
+	blockSS := make([]float64, len(hurstScales)) // sum of squared block sums
+	blockN := make([]int, len(hurstScales))
+
+	var sumS, sumSS, buyVol, totVol float64
+
+	for i := 0; i < n; i++ {
+		q, sign := cols.Qtys[i], float64(cols.Signs[i])
+
+		totVol += q
+		if sign > 0 {
+			buyVol += q
+		}
+		sumS += sign
+		sumSS += sign * sign
+
+		for k := 1; k <= maxLag && k <= i; k++ {
+			lagSum[k-1] += sign * ring[(i-k)%maxLag]
+			lagCnt[k-1]++
+		}
+		ring[i%maxLag] = sign
+
+		sv := sign * q
+		for s, m := range hurstScales {
+			blockSum[s] += sv
+			blockLen[s]++
+			if blockLen[s] == m {
+				blockSS[s] += blockSum[s] * blockSum[s]
+				blockN[s]++
+				blockSum[s], blockLen[s] = 0, 0
+			}
+		}
+	}
+
+	d := dayDiag{Trades: n, SignACF: make([]float64, maxLag)}
+	if totVol > 0 {
+		d.BuyVolFrac = buyVol / totVol
+	}
+	mean := sumS / float64(n)
+	variance := sumSS/float64(n) - mean*mean
+	if variance > 0 {
+		for k := range lagSum {
+			if lagCnt[k] > 0 {
+				d.SignACF[k] = (lagSum[k]/float64(lagCnt[k]) - mean*mean) / variance
+			}
+		}
+	}
+
+	// Var(block sum) ~ m^(2H): fit the slope of log var on log m.
+	var xs, ys []float64
+	for s, m := range hurstScales {
+		if blockN[s] < 8 {
+			continue
+		}
+		v := blockSS[s] / float64(blockN[s])
+		if v <= 0 {
+			continue
+		}
+		xs = append(xs, math.Log(float64(m)))
+		ys = append(ys, math.Log(v))
+	}
+	if len(xs) >= 3 {
+		d.Hurst = olsSlope(xs, ys) / 2
+	}
+	return d
+}
+
+func olsSlope(x, y []float64) float64 {
+	n := float64(len(x))
+	var sx, sy, sxx, sxy float64
+	for i := range x {
+		sx += x[i]
+		sy += y[i]
+		sxx += x[i] * x[i]
+		sxy += x[i] * y[i]
+	}
+	den := n*sxx - sx*sx
+	if den == 0 {
+		return 0
+	}
+	return (n*sxy - sx*sy) / den
+}
+
+func writeDiagCSV(path string, days []dayDiag, maxLag int) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(f)
+
+	header := []string{"date", "trades", "buy_vol_frac", "hurst"}
+	for k := 1; k <= maxLag; k++ {
+		header = append(header, "acf"+strconv.Itoa(k))
+	}
+	cw.Write(header)
+
+	ff := func(v float64) string { return strconv.FormatFloat(v, 'f', 6, 64) }
+	for _, d := range days {
+		rec := []string{
+			fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day),
+			strconv.Itoa(d.Trades),
+			ff(d.BuyVolFrac),
+			ff(d.Hurst),
+		}
+		for _, a := range d.SignACF {
+			rec = append(rec, ff(a))
+		}
+		cw.Write(rec)
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// printDiagMonthly prints month averages of the headline diagnostics.
+func printDiagMonthly(sym string, days []dayDiag, maxLag int) {
+	var lags []int
+	for _, k := range []int{1, 5, 10, maxLag} {
+		if k <= maxLag && (len(lags) == 0 || k > lags[len(lags)-1]) {
+			lags = append(lags, k)
+		}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "[%s]\tMONTH\tDays\tBuyVol%%\tHurst", sym)
+	for _, k := range lags {
+		fmt.Fprintf(w, "\tACF%d", k)
+	}
+	fmt.Fprintln(w)
+
+	for i := 0; i < len(days); {
+		j := i
+		var buy, hurst float64
+		acf := make([]float64, len(lags))
+		for ; j < len(days) && days[j].Year == days[i].Year && days[j].Month == days[i].Month; j++ {
+			buy += days[j].BuyVolFrac
+			hurst += days[j].Hurst
+			for li, k := range lags {
+				acf[li] += days[j].SignACF[k-1]
+			}
+		}
+		cnt := float64(j - i)
+		fmt.Fprintf(w, "\t%04d-%02d\t%d\t%.1f\t%.3f", days[i].Year, days[i].Month, j-i, 100*buy/cnt, hurst/cnt)
+		for li := range lags {
+			fmt.Fprintf(w, "\t%.3f", acf[li]/cnt)
+		}
+		fmt.Fprintln(w)
+		i = j
+	}
+	w.Flush()
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/csv"
+	"os"
+	"strconv"
+	"time"
+)
+
+// dayStatsExporter writes one CSV row per (symbol, model, horizon, day) for
+// -export-csv. The header goes out with the first row.
+type dayStatsExporter struct {
+	f          *os.File
+	cw         *csv.Writer
+	headerDone bool
+}
+
+var dayStatsHeader = []string{
+	"symbol", "model", "horizon", "date", "segment",
+	"n", "pearson_ic", "spearman_ic", "hit_rate",
+	"mean_ret_bps", "avg_trade_bps", "pnl_bps", "trades",
+}
+
+func newDayStatsExporter(path string) (*dayStatsExporter, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &dayStatsExporter{f: f, cw: csv.NewWriter(f)}, nil
+}
+
+// WriteContainer exports every day of one (model, horizon) container. Days
+// are labelled train/test with the same split the report uses.
+func (e *dayStatsExporter) WriteContainer(sym, model, horizon string, rc *ResultContainer, trainFrac float64) {
+	if !e.headerDone {
+		e.cw.Write(dayStatsHeader)
+		e.headerDone = true
+	}
+	n := len(rc.Times)
+	if n == 0 {
+		return
+	}
+	trainN := trainCount(n, trainFrac)
+	ff := func(v float64) string { return strconv.FormatFloat(v, 'g', 8, 64) }
+
+	forEachDay(rc.Times, func(start, end int) {
+		sig, ret := rc.Feats[start:end], rc.Targs[start:end]
+		segment := "train"
+		if start >= trainN {
+			segment = "test"
+		} else if end > trainN {
+			segment = "split" // day straddles the train/test boundary
+		}
+
+		var meanRet float64
+		for _, r := range ret {
+			meanRet += r
+		}
+		meanRet /= float64(end - start)
+
+		trades := signTrades(sig, ret)
+		var pnl float64
+		for _, t := range trades {
+			pnl += t
+		}
+		avgTrade := 0.0
+		if len(trades) > 0 {
+			avgTrade = pnl / float64(len(trades))
+		}
+		hit, _ := HitRateStats(sig, ret)
+
+		e.cw.Write([]string{
+			sym, model, horizon,
+			time.UnixMilli(int64(rc.Times[start])).UTC().Format("2006-01-02"),
+			segment,
+			strconv.Itoa(end - start),
+			ff(Pearson(sig, ret)),
+			ff(Spearman(sig, ret)),
+			ff(hit),
+			ff(meanRet * 1e4),
+			ff(avgTrade * 1e4),
+			ff(pnl * 1e4),
+			strconv.Itoa(len(trades)),
+		})
+	})
+}
+
+func (e *dayStatsExporter) Close() error {
+	e.cw.Flush()
+	if err := e.cw.Error(); err != nil {
+		e.f.Close()
+		return err
+	}
+	return e.f.Close()
+}
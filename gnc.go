@@ -7,9 +7,11 @@ import (
 	"iter"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strconv"
 	"sync"
-	"unsafe"
+	"time"
 )
 
 // These constants MUST match the downloader project.
@@ -136,7 +138,9 @@ type TradeBlock struct {
 	BuyerBits []uint64
 }
 
-// mapTradeBlock creates a view over raw blob without extra allocations.
+// mapTradeBlock creates a view over raw blob. On amd64/arm64 the columns
+// alias raw (no allocations); elsewhere they are decoded into fresh slices
+// (see tradeblock_unsafe.go / tradeblock_portable.go).
 func mapTradeBlock(raw []byte) (*TradeBlock, error) {
 	h, err := parseTBHeader(raw, uint64(len(raw)))
 	if err != nil {
@@ -152,16 +156,7 @@ func mapTradeBlock(raw []byte) (*TradeBlock, error) {
 	}
 
 	tb := &TradeBlock{Count: count}
-	base := unsafe.Pointer(&raw[0])
-
-	tb.AggTradeIDs = unsafe.Slice((*uint64)(unsafe.Add(base, uintptr(h.OffAgg))), count)
-	tb.Prices = unsafe.Slice((*float64)(unsafe.Add(base, uintptr(h.OffPrice))), count)
-	tb.Quantities = unsafe.Slice((*float64)(unsafe.Add(base, uintptr(h.OffQty))), count)
-	tb.FirstTradeIDs = unsafe.Slice((*uint64)(unsafe.Add(base, uintptr(h.OffFirst))), count)
-	tb.LastTradeIDs = unsafe.Slice((*uint64)(unsafe.Add(base, uintptr(h.OffLast))), count)
-	tb.Times = unsafe.Slice((*int64)(unsafe.Add(base, uintptr(h.OffTime))), count)
-	tb.BuyerBits = unsafe.Slice((*uint64)(unsafe.Add(base, uintptr(h.OffBits))), int(h.BitWords))
-
+	tb.mapColumns(raw, h)
 	return tb, nil
 }
 
@@ -178,13 +173,18 @@ func (tb *TradeBlock) IsBuyerMaker(i int) bool {
 // --- DayColumns (simple SoA view used by RunStream) ---
 
 // DayColumns is the SoA representation of a single day's trades,
-// used by the streaming feature engine. Right now we only need
-// time/price/quantity for the continuous models.
+// used by the streaming feature engine: time/price/quantity plus the taker
+// side of each trade.
 type DayColumns struct {
 	Count  int
 	Times  []int64
 	Prices []float64
 	Qtys   []float64
+	Signs  []int8 // +1 buyer-initiated, -1 seller-initiated (buyer-maker bit)
+
+	// CumNotional[i] = sum(price*qty) over trades 0..i; only filled when a
+	// dollar-volume horizon is configured (FillCumNotional).
+	CumNotional []float64
 }
 
 // DayColumnPool reduces allocation pressure (critical for GOGC=200).
@@ -196,6 +196,7 @@ var DayColumnPool = sync.Pool{
 			Times:  make([]int64, 0, initCap),
 			Prices: make([]float64, 0, initCap),
 			Qtys:   make([]float64, 0, initCap),
+			Signs:  make([]int8, 0, initCap),
 		}
 	},
 }
@@ -206,6 +207,23 @@ func (c *DayColumns) Reset() {
 	c.Times = c.Times[:0]
 	c.Prices = c.Prices[:0]
 	c.Qtys = c.Qtys[:0]
+	c.Signs = c.Signs[:0]
+	c.CumNotional = c.CumNotional[:0]
+}
+
+// FillCumNotional computes the running traded notional (quote units).
+func (c *DayColumns) FillCumNotional() {
+	n := c.Count
+	if cap(c.CumNotional) < n {
+		c.CumNotional = make([]float64, n)
+	}
+	c.CumNotional = c.CumNotional[:n]
+
+	var sum float64
+	for i := 0; i < n; i++ {
+		sum += c.Prices[i] * c.Qtys[i]
+		c.CumNotional[i] = sum
+	}
 }
 
 // FillFromTradeBlock copies the TBV1 SoA into the DayColumns view.
@@ -226,16 +244,30 @@ func (c *DayColumns) FillFromTradeBlock(tb *TradeBlock) {
 		c.Qtys = c.Qtys[:n]
 	}
 
+	if cap(c.Signs) < n {
+		c.Signs = make([]int8, n)
+	} else {
+		c.Signs = c.Signs[:n]
+	}
+
 	copy(c.Times, tb.Times)
 	copy(c.Prices, tb.Prices)
 	copy(c.Qtys, tb.Quantities)
+	for i := range c.Signs {
+		c.Signs[i] = 1
+		if tb.IsBuyerMaker(i) {
+			c.Signs[i] = -1
+		}
+	}
 
 	c.Count = n
 }
 
 // ofiTask identifies a single day (year, month, day) for one symbol.
+// Size is the blob length from the index row, used for scheduling.
 type ofiTask struct {
 	Year, Month, Day int
+	Size             uint64
 }
 
 // LoadGNCFile locates and reads a single TBV1 blob for (sym, day) into buf.
@@ -244,12 +276,15 @@ type ofiTask struct {
 // NOTE: Name kept as LoadGNCFile for API compatibility with existing code;
 // it now actually loads a TBV1 trade-block blob.
 func LoadGNCFile(baseDir, sym string, t ofiTask, buf *[]byte) bool {
-	dir := filepath.Join(baseDir, sym, sprintfYear(t.Year), sprintfMonth(t.Month))
+	dir, archived := resolveMonthDir(filepath.Join(baseDir, sym, sprintfYear(t.Year), sprintfMonth(t.Month)))
 	idxPath := filepath.Join(dir, "index.quantdev")
 	dataPath := filepath.Join(dir, "data.quantdev")
 
-	offset, length := findBlobOffset(idxPath, t.Day)
+	offset, length, sum := findBlobOffset(idxPath, t.Day)
 	if length == 0 {
+		if archived != "" {
+			fmt.Printf("[%s] %04d-%02d-%02d is archived at %s (not readable)\n", sym, t.Year, t.Month, t.Day, archived)
+		}
 		return false
 	}
 
@@ -276,11 +311,36 @@ func LoadGNCFile(baseDir, sym string, t ofiTask, buf *[]byte) bool {
 	if _, err := io.ReadFull(f, *buf); err != nil {
 		return false
 	}
+	if VerifyChecksums && blobChecksum(*buf) != sum {
+		fmt.Printf("[%s] CHECKSUM_MISMATCH %04d-%02d-%02d: blob does not match its index checksum, day skipped\n", sym, t.Year, t.Month, t.Day)
+		return false
+	}
 	return true
 }
 
+// loadBlobRows reads only the TBV1 header of (sym, day) and returns its
+// trade count, or 0 if the day is missing or unreadable.
+func loadBlobRows(baseDir, sym string, t ofiTask) int {
+	dir, _ := resolveMonthDir(filepath.Join(baseDir, sym, sprintfYear(t.Year), sprintfMonth(t.Month)))
+	offset, length, _ := findBlobOffset(filepath.Join(dir, "index.quantdev"), t.Day)
+	if length < TBHdrSize {
+		return 0
+	}
+	f, err := os.Open(filepath.Join(dir, "data.quantdev"))
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+
+	var hdr [16]byte
+	if _, err := f.ReadAt(hdr[:], int64(offset)); err != nil || string(hdr[0:4]) != TBMagic {
+		return 0
+	}
+	return int(binary.LittleEndian.Uint64(hdr[8:16]))
+}
+
 // InflateGNC decodes a TBV1 blob into DayColumns by mapping the TradeBlock
-// and copying just the SoA slices we care about (time, price, qty).
+// and copying just the SoA slices we care about (time, price, qty, side).
 //
 // Signature is kept as (int, error) for compatibility with the previous code.
 func InflateGNC(rawBlob []byte, cols *DayColumns) (int, error) {
@@ -298,22 +358,67 @@ func InflateGNC(rawBlob []byte, cols *DayColumns) (int, error) {
 	return cols.Count, nil
 }
 
+// skippedDay is a day dropped for having fewer than MinRowsPerDay trades.
+type skippedDay struct {
+	Date time.Time
+	Rows int
+}
+
+// isShortDay reports whether a decoded day falls below MinRowsPerDay.
+func isShortDay(rows int) bool { return MinRowsPerDay > 0 && rows < MinRowsPerDay }
+
+// printSkippedDays prints the load failures and short days of one pass, in
+// date order. Nothing is printed when no day was skipped.
+func printSkippedDays(sym string, failed int, short []skippedDay) {
+	if failed == 0 && len(short) == 0 {
+		return
+	}
+	fmt.Printf("[%s] Skipped %d days that failed to load and %d short days (< %d rows)", sym, failed, len(short), MinRowsPerDay)
+	sort.Slice(short, func(i, j int) bool { return short[i].Date.Before(short[j].Date) })
+	for i, d := range short {
+		if i == 0 {
+			fmt.Print(":")
+		}
+		fmt.Printf(" %s (%d)", d.Date.Format("2006-01-02"), d.Rows)
+	}
+	fmt.Println()
+}
+
 // --- Discovery helpers over the TBV1 index tree ---
 
-// discoverSymbols yields all symbols (top-level dirs) under BaseDir.
+// discoverSymbols yields all symbols (top-level dirs) under BaseDir, plus
+// the symbols routed to other roots via SymbolRoots. A symbol is yielded
+// once, from the root SymbolRoot reports for it.
 func discoverSymbols() iter.Seq[string] {
 	return func(yield func(string) bool) {
-		entries, _ := os.ReadDir(BaseDir)
-		for _, e := range entries {
-			if !e.IsDir() {
-				continue
-			}
-			name := e.Name()
-			if len(name) == 0 || name[0] == '.' || name == "features" {
-				continue
+		roots := []string{BaseDir}
+		for _, r := range SymbolRoots {
+			if !slices.Contains(roots, r) {
+				roots = append(roots, r)
 			}
-			if !yield(name) {
-				return
+		}
+
+		seen := make(map[string]struct{})
+		for _, root := range roots {
+			entries, _ := os.ReadDir(root)
+			for _, e := range entries {
+				if !e.IsDir() {
+					continue
+				}
+				name := e.Name()
+				if len(name) == 0 || name[0] == '.' || name == "features" {
+					continue
+				}
+				if SymbolRoot(name) != root {
+					continue
+				}
+				if _, ok := seen[name]; ok {
+					continue
+				}
+				seen[name] = struct{}{}
+				if !yield(name) {
+					return
+				}
 			}
 		}
 	}
@@ -321,14 +426,20 @@ func discoverSymbols() iter.Seq[string] {
 
 // discoverTasks yields all (year, month, day) tasks for a symbol.
 // Reads 26-byte index rows: Day[2] + Offset[8] + Length[8] + Checksum[8].
+//
+// Year directories are enumerated concurrently (one goroutine per year);
+// results are then yielded in year order so the output is deterministic.
 func discoverTasks(sym string) iter.Seq[ofiTask] {
 	return func(yield func(ofiTask) bool) {
-		root := filepath.Join(BaseDir, sym)
+		root := filepath.Join(SymbolRoot(sym), sym)
 		years, err := os.ReadDir(root)
 		if err != nil {
 			return
 		}
-		for _, y := range years {
+
+		perYear := make([][]ofiTask, len(years))
+		var wg sync.WaitGroup
+		for i, y := range years {
 			if !y.IsDir() || len(y.Name()) != 4 {
 				continue
 			}
@@ -336,73 +447,92 @@ func discoverTasks(sym string) iter.Seq[ofiTask] {
 			if err != nil {
 				continue
 			}
+			wg.Add(1)
+			go func(slot, year int, yearDir string) {
+				defer wg.Done()
+				perYear[slot] = discoverYearTasks(yearDir, year)
+			}(i, year, filepath.Join(root, y.Name()))
+		}
+		wg.Wait()
 
-			months, err := os.ReadDir(filepath.Join(root, y.Name()))
-			if err != nil {
-				continue
-			}
-			for _, m := range months {
-				if !m.IsDir() || len(m.Name()) != 2 {
-					continue
-				}
-				month, err := strconv.Atoi(m.Name())
-				if err != nil {
-					continue
+		for _, tasks := range perYear {
+			for _, t := range tasks {
+				if !yield(t) {
+					return
 				}
+			}
+		}
+	}
+}
 
-				idxPath := filepath.Join(root, y.Name(), m.Name(), "index.quantdev")
-				f, err := os.Open(idxPath)
-				if err != nil {
-					continue
-				}
+// discoverYearTasks reads every month index under one year directory.
+func discoverYearTasks(yearDir string, year int) []ofiTask {
+	months, err := os.ReadDir(yearDir)
+	if err != nil {
+		return nil
+	}
+
+	var tasks []ofiTask
+	for _, m := range months {
+		if !m.IsDir() || len(m.Name()) != 2 {
+			continue
+		}
+		month, err := strconv.Atoi(m.Name())
+		if err != nil {
+			continue
+		}
+
+		monthDir, _ := resolveMonthDir(filepath.Join(yearDir, m.Name()))
+		idxPath := filepath.Join(monthDir, "index.quantdev")
+		f, err := os.Open(idxPath)
+		if err != nil {
+			continue
+		}
 
-				var hdr [16]byte
-				if _, err := io.ReadFull(f, hdr[:]); err == nil && string(hdr[0:4]) == IdxMagic {
-					count := binary.LittleEndian.Uint64(hdr[8:16])
-					var row [26]byte
-					for i := uint64(0); i < count; i++ {
-						if _, err := io.ReadFull(f, row[:]); err != nil {
-							break
-						}
-						day := int(binary.LittleEndian.Uint16(row[0:2]))
-						if !yield(ofiTask{year, month, day}) {
-							f.Close()
-							return
-						}
-					}
+		var hdr [16]byte
+		if _, err := io.ReadFull(f, hdr[:]); err == nil && string(hdr[0:4]) == IdxMagic {
+			count := binary.LittleEndian.Uint64(hdr[8:16])
+			var row [26]byte
+			for i := uint64(0); i < count; i++ {
+				if _, err := io.ReadFull(f, row[:]); err != nil {
+					break
 				}
-				f.Close()
+				day := int(binary.LittleEndian.Uint16(row[0:2]))
+				size := binary.LittleEndian.Uint64(row[10:18])
+				tasks = append(tasks, ofiTask{year, month, day, size})
 			}
 		}
+		f.Close()
 	}
+	return tasks
 }
 
-// findBlobOffset scans a single index.quantdev for a given day.
-func findBlobOffset(idxPath string, day int) (uint64, uint64) {
+// findBlobOffset scans a single index.quantdev for a given day and returns
+// the blob's offset, length and checksum.
+func findBlobOffset(idxPath string, day int) (offset, length uint64, sum [8]byte) {
 	f, err := os.Open(idxPath)
 	if err != nil {
-		return 0, 0
+		return 0, 0, sum
 	}
 	defer f.Close()
 
 	var hdr [16]byte
 	if _, err := io.ReadFull(f, hdr[:]); err != nil || string(hdr[0:4]) != IdxMagic {
-		return 0, 0
+		return 0, 0, sum
 	}
 	count := binary.LittleEndian.Uint64(hdr[8:16])
 
 	var row [26]byte
 	for i := uint64(0); i < count; i++ {
 		if _, err := io.ReadFull(f, row[:]); err != nil {
-			return 0, 0
+			return 0, 0, sum
 		}
 		if int(binary.LittleEndian.Uint16(row[0:2])) == day {
-			offset := binary.LittleEndian.Uint64(row[2:10])
-			length := binary.LittleEndian.Uint64(row[10:18])
-			return offset, length
+			copy(sum[:], row[18:26])
+			return binary.LittleEndian.Uint64(row[2:10]), binary.LittleEndian.Uint64(row[10:18]), sum
 		}
 	}
-	return 0, 0
+	return 0, 0, sum
 }
 
 func sprintfYear(y int) string  { return strconv.Itoa(y) }
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// HorizonUnit selects how a forward horizon is measured.
+type HorizonUnit int
+
+const (
+	HorizonTime   HorizonUnit = iota // wall clock, Value in ms
+	HorizonTicks                     // number of aggTrades after entry
+	HorizonDollar                    // cumulative traded notional after entry, Value in quote units
+)
+
+// Horizon is a forward-return horizon with an explicit unit, parsed from
+// strings like "60s", "15m", "1h", "100t" or "10M$".
+type Horizon struct {
+	Unit  HorizonUnit
+	Value float64
+}
+
+// ParseHorizon parses "<number><unit>" where unit is ms, s, m, h (time),
+// t (ticks) or $ (dollar volume, with an optional K/M/B multiplier:
+// "2M$", "5e6$").
+func ParseHorizon(s string) (Horizon, error) {
+	s = strings.TrimSpace(s)
+	if num, ok := strings.CutSuffix(s, "$"); ok {
+		scale := 1.0
+		switch {
+		case strings.HasSuffix(num, "K"):
+			scale, num = 1e3, strings.TrimSuffix(num, "K")
+		case strings.HasSuffix(num, "M"):
+			scale, num = 1e6, strings.TrimSuffix(num, "M")
+		case strings.HasSuffix(num, "B"):
+			scale, num = 1e9, strings.TrimSuffix(num, "B")
+		}
+		v, err := strconv.ParseFloat(num, 64)
+		if err != nil || v <= 0 {
+			return Horizon{}, fmt.Errorf("invalid horizon %q", s)
+		}
+		return Horizon{Unit: HorizonDollar, Value: v * scale}, nil
+	}
+	units := []struct {
+		suffix string
+		unit   HorizonUnit
+		scale  float64
+	}{
+		{"ms", HorizonTime, 1},
+		{"s", HorizonTime, 1000},
+		{"m", HorizonTime, 60 * 1000},
+		{"h", HorizonTime, 60 * 60 * 1000},
+		{"t", HorizonTicks, 1},
+	}
+	for _, u := range units {
+		num, ok := strings.CutSuffix(s, u.suffix)
+		if !ok {
+			continue
+		}
+		v, err := strconv.ParseFloat(num, 64)
+		if err != nil || v <= 0 {
+			return Horizon{}, fmt.Errorf("invalid horizon %q", s)
+		}
+		v *= u.scale
+		if v != float64(int64(v)) {
+			return Horizon{}, fmt.Errorf("horizon %q must be a whole number of ms/ticks", s)
+		}
+		return Horizon{Unit: u.unit, Value: v}, nil
+	}
+	return Horizon{}, fmt.Errorf("horizon %q has no unit (ms, s, m, h, t, $)", s)
+}
+
+// String returns the canonical form used for report labels and JSON keys.
+func (h Horizon) String() string {
+	n := int64(h.Value)
+	switch h.Unit {
+	case HorizonTicks:
+		return strconv.FormatInt(n, 10) + "t"
+	case HorizonDollar:
+		switch {
+		case h.Value >= 1e9 && h.Value == float64(int64(h.Value/1e9))*1e9:
+			return strconv.FormatInt(int64(h.Value/1e9), 10) + "B$"
+		case h.Value >= 1e6 && h.Value == float64(int64(h.Value/1e6))*1e6:
+			return strconv.FormatInt(int64(h.Value/1e6), 10) + "M$"
+		case h.Value >= 1e3 && h.Value == float64(int64(h.Value/1e3))*1e3:
+			return strconv.FormatInt(int64(h.Value/1e3), 10) + "K$"
+		}
+		return strconv.FormatFloat(h.Value, 'f', -1, 64) + "$"
+	default:
+		switch {
+		case n%(60*60*1000) == 0:
+			return strconv.FormatInt(n/(60*60*1000), 10) + "h"
+		case n%(60*1000) == 0:
+			return strconv.FormatInt(n/(60*1000), 10) + "m"
+		case n%1000 == 0:
+			return strconv.FormatInt(n/1000, 10) + "s"
+		}
+		return strconv.FormatInt(n, 10) + "ms"
+	}
+}
+
+// ExitIndex returns the index of the exit trade for an entry at tick index
+// entry, or -1 when the horizon runs past the end of the day. Dollar
+// horizons need cols.CumNotional (see FillCumNotional).
+func (h Horizon) ExitIndex(cols *DayColumns, entry int) int {
+	n := cols.Count
+	switch h.Unit {
+	case HorizonDollar:
+		cum := cols.CumNotional
+		target := cum[entry] + h.Value
+		if target > cum[n-1] {
+			return -1
+		}
+		// First trade at which cumulative notional after entry reaches the target.
+		return entry + sort.Search(n-entry, func(k int) bool {
+			return cum[entry+k] >= target
+		})
+	case HorizonTicks:
+		exit := entry + int(h.Value)
+		if exit >= n {
+			return -1
+		}
+		return exit
+	default:
+		targetT := cols.Times[entry] + int64(h.Value)
+		if targetT > cols.Times[n-1] {
+			return -1
+		}
+		// Binary search for first tick with time >= targetT.
+		idx := entry + sort.Search(n-entry, func(k int) bool {
+			return cols.Times[entry+k] >= targetT
+		})
+		if idx == n {
+			return -1
+		}
+		return idx
+	}
+}
+
+// hasDollarHorizon reports whether any horizon needs cumulative notional.
+func hasDollarHorizon(hs []Horizon) bool {
+	for _, h := range hs {
+		if h.Unit == HorizonDollar {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseHorizons parses a comma-separated horizon list.
+func ParseHorizons(list string) ([]Horizon, error) {
+	var out []Horizon
+	for _, part := range strings.Split(list, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		h, err := ParseHorizon(part)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, h)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("empty horizon list")
+	}
+	return out, nil
+}
+
+// SetHorizons replaces the configured horizons and their labels.
+func SetHorizons(hs []Horizon) {
+	Horizons = hs
+	HorizonLabels = horizonLabels(hs)
+}
+
+func horizonLabels(hs []Horizon) []string {
+	labels := make([]string, len(hs))
+	for i, h := range hs {
+		labels[i] = h.String()
+	}
+	return labels
+}
+
+func mustParseHorizons(list string) []Horizon {
+	hs, err := ParseHorizons(list)
+	if err != nil {
+		panic(err)
+	}
+	return hs
+}
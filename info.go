@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+)
+
+// symbolInventory aggregates the stored days of one symbol for RunInfo.
+type symbolInventory struct {
+	First, Last string // YYYY-MM-DD
+	Days        int
+	Rows        uint64
+	BlobBytes   int64 // live blobs referenced by the index
+	DataBytes   int64 // data.quantdev sizes, superseded generations included
+	Archived    int   // months served from cold storage
+	Updated     time.Time
+	Unreadable  int // indexed days whose TBV1 header could not be read
+
+	// From the daystats sidecars (see RunDayStats); stale entries are skipped.
+	StatsDays int
+	Notional  float64
+}
+
+// RunInfo prints one row per symbol summarizing what is stored: date range,
+// day and row counts (from the TBV1 headers), blob bytes and the newest
+// index modification time. Only headers are read, never whole blobs;
+// notional comes from the daystats sidecars and covers STATS_DAYS days.
+func RunInfo(onlySym string) {
+	start := time.Now()
+
+	fmt.Println(">>> DATA INVENTORY <<<")
+	fmt.Printf("BaseDir: %s (+%d routed symbols)\n\n", BaseDir, len(SymbolRoots))
+
+	var order []string
+	inv := make(map[string]*symbolInventory)
+	for _, m := range listMonthDirs(onlySym) {
+		s := inv[m.Sym]
+		if s == nil {
+			s = &symbolInventory{}
+			inv[m.Sym] = s
+			order = append(order, m.Sym)
+		}
+		if err := s.addMonth(m); err != nil {
+			fmt.Printf("  [%s] %04d-%02d  ERROR: %v\n", m.Sym, m.Year, m.Month, err)
+		}
+	}
+	if len(order) == 0 {
+		fmt.Println("No symbols discovered under BaseDir.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SYMBOL\tFIRST_DAY\tLAST_DAY\tDAYS\tROWS\tBLOB_MB\tDATA_MB\tARCHIVED\tUNREADABLE\tSTATS_DAYS\tNOTIONAL_M\tUPDATED")
+	fmt.Fprintln(w, "------\t---------\t--------\t----\t----\t-------\t-------\t--------\t----------\t----------\t----------\t-------")
+	var totDays int
+	var totRows uint64
+	var totBlob, totData int64
+	for _, sym := range order {
+		s := inv[sym]
+		first, last, updated := "-", "-", "-"
+		if s.Days > 0 {
+			first, last = s.First, s.Last
+		}
+		if !s.Updated.IsZero() {
+			updated = s.Updated.Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%.1f\t%.1f\t%d\t%d\t%d\t%.1f\t%s\n",
+			sym, first, last, s.Days, s.Rows, float64(s.BlobBytes)/1e6, float64(s.DataBytes)/1e6,
+			s.Archived, s.Unreadable, s.StatsDays, s.Notional/1e6, updated)
+		totDays += s.Days
+		totRows += s.Rows
+		totBlob += s.BlobBytes
+		totData += s.DataBytes
+	}
+	fmt.Fprintf(w, "TOTAL\t\t\t%d\t%d\t%.1f\t%.1f\t\t\t\t\t\n", totDays, totRows, float64(totBlob)/1e6, float64(totData)/1e6)
+	w.Flush()
+
+	fmt.Printf("\n[info] %d symbols scanned in %s\n", len(order), time.Since(start))
+}
+
+// addMonth folds one month directory into the inventory. Duplicate index
+// rows (superseded repairs) count once, matching findBlobOffset.
+func (s *symbolInventory) addMonth(m monthDir) error {
+	dir, archived := resolveMonthDir(m.Dir)
+	idxPath := filepath.Join(dir, "index.quantdev")
+	dataPath := filepath.Join(dir, "data.quantdev")
+
+	fi, err := os.Stat(idxPath)
+	if err != nil {
+		return nil // empty month directory
+	}
+	if archived != "" {
+		s.Archived++
+	}
+	if fi.ModTime().After(s.Updated) {
+		s.Updated = fi.ModTime()
+	}
+
+	_, rows, err := readIndexFile(idxPath)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(dataPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if dfi, err := f.Stat(); err == nil {
+		s.DataBytes += dfi.Size()
+	}
+
+	stats, err := loadDayStats(m.Dir)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[int]bool, len(rows))
+	minDay, maxDay := 0, 0
+	var hdr [16]byte
+	for _, r := range rows {
+		if seen[r.Day] {
+			continue
+		}
+		seen[r.Day] = true
+		if minDay == 0 || r.Day < minDay {
+			minDay = r.Day
+		}
+		if r.Day > maxDay {
+			maxDay = r.Day
+		}
+		s.Days++
+		s.BlobBytes += int64(r.Length)
+		if ds, ok := stats[r.Day]; ok && ds.Checksum == hex.EncodeToString(r.Checksum[:]) {
+			s.StatsDays++
+			s.Notional += ds.DollarVolume
+		}
+
+		if r.Length < TBHdrSize {
+			s.Unreadable++
+			continue
+		}
+		if _, err := f.ReadAt(hdr[:], int64(r.Offset)); err != nil || string(hdr[0:4]) != TBMagic {
+			s.Unreadable++
+			continue
+		}
+		s.Rows += binary.LittleEndian.Uint64(hdr[8:16])
+	}
+	if len(seen) == 0 {
+		return nil
+	}
+
+	// Months arrive in date order from listMonthDirs.
+	if s.First == "" {
+		s.First = fmt.Sprintf("%04d-%02d-%02d", m.Year, m.Month, minDay)
+	}
+	s.Last = fmt.Sprintf("%04d-%02d-%02d", m.Year, m.Month, maxDay)
+	return nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// LedgerPath is the append-only record of OOS evaluations (-ledger).
+const LedgerPath = "reports/oos_ledger.jsonl"
+
+// ledgerEntry is one OOS evaluation of a symbol. OOSFrom is set for the
+// extra -oos-dates sections, whose test segment starts at that date.
+type ledgerEntry struct {
+	Time       string   `json:"time"`
+	Symbol     string   `json:"symbol"`
+	ConfigHash string   `json:"configHash"`
+	Models     []string `json:"models"`
+	Horizons   []string `json:"horizons"`
+	OOSFrom    string   `json:"oosFrom,omitempty"`
+}
+
+// configHash fingerprints the settings that change what an OOS evaluation
+// measures, so ledger entries from identical runs can be told apart from tweaks.
+func configHash(trainFrac float64) string {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s|%d|%s|%d|%g|%s|%s|%g",
+		strings.Join(HorizonLabels, ","), SamplingRateSec, ReturnDef, VWAPTrades,
+		DaysPerYear, SignalDir, SignalAlign, trainFrac)
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// ledgerPriorEvals counts previous OOS evaluations of each model on sym.
+// A missing ledger means no prior evaluations.
+func ledgerPriorEvals(sym string) map[string]int {
+	counts := make(map[string]int)
+	f, err := os.Open(LedgerPath)
+	if err != nil {
+		return counts
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 1<<20)
+	for sc.Scan() {
+		var e ledgerEntry
+		if json.Unmarshal(sc.Bytes(), &e) != nil || e.Symbol != sym {
+			continue
+		}
+		for _, m := range e.Models {
+			counts[m]++
+		}
+	}
+	return counts
+}
+
+// ledgerAppend records one OOS evaluation; a non-zero oosFrom marks an
+// -oos-dates boundary instead of the trainFrac split.
+func ledgerAppend(sym string, models []string, trainFrac float64, oosFrom time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(LedgerPath), 0o755); err != nil {
+		return err
+	}
+	e := ledgerEntry{
+		Time:       time.Now().UTC().Format(time.RFC3339),
+		Symbol:     sym,
+		ConfigHash: configHash(trainFrac),
+		Models:     models,
+		Horizons:   HorizonLabels,
+	}
+	if !oosFrom.IsZero() {
+		e.OOSFrom = oosFrom.Format("2006-01-02")
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(LedgerPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -1,9 +1,14 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
+	"runtime"
 	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
 )
 
 func main() {
@@ -11,18 +16,270 @@ func main() {
 	debug.SetGCPercent(200)
 
 	if len(os.Args) < 2 {
-		fmt.Println("Usage: go run . [test|probe]")
+		fmt.Println("Usage: go run . [test|probe|info|daystats|diag|warmup|replay|trace|verify|ml-export|archive|unarchive|compact] [flags]")
 		return
 	}
 
 	switch os.Args[1] {
 	case "test":
 		// Full OOS research run (writes Continuous_Algo_Report_OOS.txt).
+		parseTestFlags(os.Args[2:])
 		RunTest()
 	case "probe":
 		// Structural sanity check of data under BaseDir.
 		RunProbe()
+	case "info":
+		// Inventory of stored data per symbol (index + TBV1 headers only).
+		fs := flag.NewFlagSet("info", flag.ExitOnError)
+		sym := fs.String("sym", "", "only this symbol (default all)")
+		fs.Parse(os.Args[2:])
+		RunInfo(*sym)
+	case "daystats":
+		// Precompute per-day raw statistics into each month's sidecar.
+		fs := flag.NewFlagSet("daystats", flag.ExitOnError)
+		sym := fs.String("sym", "", "only this symbol (default all)")
+		fs.BoolVar(&DryRun, "dry-run", DryRun, "list the days that would be computed and why; writes nothing")
+		fs.Parse(os.Args[2:])
+		RunDayStats(*sym)
+	case "diag":
+		// Raw order-flow diagnostics per day (no models, no features).
+		fs := flag.NewFlagSet("diag", flag.ExitOnError)
+		sym := fs.String("sym", "", "only this symbol (default all)")
+		lags := fs.Int("lags", 50, "max lag of the trade-sign autocorrelation")
+		fs.IntVar(&MinRowsPerDay, "min-rows", MinRowsPerDay, "skip days with fewer trades (0 = keep all)")
+		fs.Parse(os.Args[2:])
+		RunDiag(*sym, *lags)
+	case "warmup":
+		// Cold-start vs carried-state convergence of every model on one day.
+		fs := flag.NewFlagSet("warmup", flag.ExitOnError)
+		sym := fs.String("sym", Symbol(), "symbol")
+		day := fs.String("day", "", "day to study, YYYY-MM-DD (default: median-size day)")
+		tol := fs.Float64("tol", 0.01, "convergence band as a fraction of the feature's std")
+		fs.Parse(os.Args[2:])
+		RunWarmup(*sym, *day, *tol)
+	case "replay":
+		// Per-trade CSV of every model's raw output over one day.
+		fs := flag.NewFlagSet("replay", flag.ExitOnError)
+		sym := fs.String("sym", Symbol(), "symbol")
+		day := fs.String("day", "", "day to replay, YYYY-MM-DD")
+		models := fs.String("models", "", "comma-separated model names (default: all)")
+		out := fs.String("out", "", "output CSV (default replay_<SYM>_<DAY>.csv)")
+		ladder := fs.String("imb-ladder", "", "comma-separated trade-window lengths of the Imb_L<n> imbalance models")
+		fs.BoolVar(&SpreadProxyModel, "spread-proxy", SpreadProxyModel, "add the trade-implied spread model (Spread_Proxy)")
+		fs.BoolVar(&SizeAnomalyModel, "size-anomaly", SizeAnomalyModel, "add the signed trade-size z-score model (Size_Anomaly)")
+		fs.BoolVar(&SignRunModels, "sign-run", SignRunModels, "add the trade-sign run-length and reversal-rate models")
+		fs.BoolVar(&ElasticityAsymModel, "elast-asym", ElasticityAsymModel, "add the buy/sell price-elasticity models (Elast_Buy, Elast_Sell, Elast_Asym)")
+		fs.BoolVar(&DollarVolume, "dollar", DollarVolume, "add quote-notional weighted copies (<Model>_Dollar) of the volume-driven models")
+		fs.Parse(os.Args[2:])
+		if err := setImbalanceLadder(*ladder); err != nil {
+			fmt.Println("Invalid -imb-ladder:", err)
+			os.Exit(2)
+		}
+		RunReplay(*sym, *day, *models, *out)
+	case "trace":
+		// Model internal state next to the outputs, for a range of trades.
+		fs := flag.NewFlagSet("trace", flag.ExitOnError)
+		sym := fs.String("sym", Symbol(), "symbol")
+		day := fs.String("day", "", "day to trace, YYYY-MM-DD")
+		from := fs.Int("from-row", 0, "first trade (0-based) to dump")
+		rows := fs.Int("rows", 50, "trades to dump (0 = to the end of the day)")
+		models := fs.String("models", "", "comma-separated model names (default: all)")
+		out := fs.String("out", "", "output CSV (default trace_<SYM>_<DAY>.csv)")
+		ladder := fs.String("imb-ladder", "", "comma-separated trade-window lengths of the Imb_L<n> imbalance models")
+		fs.BoolVar(&SpreadProxyModel, "spread-proxy", SpreadProxyModel, "add the trade-implied spread model (Spread_Proxy)")
+		fs.BoolVar(&SizeAnomalyModel, "size-anomaly", SizeAnomalyModel, "add the signed trade-size z-score model (Size_Anomaly)")
+		fs.BoolVar(&SignRunModels, "sign-run", SignRunModels, "add the trade-sign run-length and reversal-rate models")
+		fs.BoolVar(&ElasticityAsymModel, "elast-asym", ElasticityAsymModel, "add the buy/sell price-elasticity models (Elast_Buy, Elast_Sell, Elast_Asym)")
+		fs.BoolVar(&DollarVolume, "dollar", DollarVolume, "add quote-notional weighted copies (<Model>_Dollar) of the volume-driven models")
+		fs.Parse(os.Args[2:])
+		if err := setImbalanceLadder(*ladder); err != nil {
+			fmt.Println("Invalid -imb-ladder:", err)
+			os.Exit(2)
+		}
+		RunTrace(*sym, *day, *models, *out, *from, *rows)
+	case "verify":
+		// Bit-for-bit replay of sampled days plus a merge-order check.
+		fs := flag.NewFlagSet("verify", flag.ExitOnError)
+		sym := fs.String("sym", Symbol(), "symbol")
+		days := fs.Int("days", 5, "number of evenly spaced days to replay (0 = all)")
+		fs.Parse(os.Args[2:])
+		RunVerify(*sym, *days)
+	case "ml-export":
+		// Grid-sampled features + forward-return labels for external trainers.
+		fs := flag.NewFlagSet("ml-export", flag.ExitOnError)
+		sym := fs.String("sym", Symbol(), "symbol")
+		out := fs.String("out", "ml_export", "output root (IS/ and OOS/ subdirectories)")
+		interval := fs.Duration("interval", 10*time.Second, "grid spacing")
+		stale := fs.Duration("stale", time.Minute, "leave a row empty if the last trade is older than this (0 = never)")
+		label := fs.String("label-horizon", "60s", "forward-return horizon of the label (ms/s/m/h/t/$)")
+		models := fs.String("models", "", "comma-separated model names (default: all)")
+		ladder := fs.String("imb-ladder", "", "comma-separated trade-window lengths of the Imb_L<n> imbalance models")
+		fs.BoolVar(&SpreadProxyModel, "spread-proxy", SpreadProxyModel, "add the trade-implied spread model (Spread_Proxy)")
+		fs.BoolVar(&SizeAnomalyModel, "size-anomaly", SizeAnomalyModel, "add the signed trade-size z-score model (Size_Anomaly)")
+		fs.BoolVar(&SignRunModels, "sign-run", SignRunModels, "add the trade-sign run-length and reversal-rate models")
+		fs.BoolVar(&ElasticityAsymModel, "elast-asym", ElasticityAsymModel, "add the buy/sell price-elasticity models (Elast_Buy, Elast_Sell, Elast_Asym)")
+		fs.BoolVar(&DollarVolume, "dollar", DollarVolume, "add quote-notional weighted copies (<Model>_Dollar) of the volume-driven models")
+		fs.StringVar(&ReturnDef, "returns", ReturnDef, "label return definition: log, simple or vwap")
+		fs.IntVar(&MinRowsPerDay, "min-rows", MinRowsPerDay, "skip days with fewer trades (0 = keep all)")
+		fs.Float64Var(&TrainFrac, "train-frac", TrainFrac, "chronological fraction of trades in the train (IS) segment, as in the test report")
+		keepShort := fs.Bool("keep-short", false, "still export days below -min-rows, as <DAY>.short.csv.gz")
+		fs.BoolVar(&DryRun, "dry-run", DryRun, "list the days and output files; writes nothing")
+		fs.Parse(os.Args[2:])
+		if err := setImbalanceLadder(*ladder); err != nil {
+			fmt.Println("Invalid -imb-ladder:", err)
+			os.Exit(2)
+		}
+		h, err := ParseHorizon(*label)
+		if err != nil {
+			fmt.Println("Invalid -label-horizon:", err)
+			os.Exit(2)
+		}
+		RunMLExport(*sym, *out, *interval, *stale, h, *models, *keepShort)
+	case "archive":
+		// Move old months to cold storage, leaving stubs behind.
+		fs := flag.NewFlagSet("archive", flag.ExitOnError)
+		before := fs.String("before", "", "archive months ending before this date (YYYY-MM-DD)")
+		dest := fs.String("dest", "", "cold-storage root directory")
+		fs.Parse(os.Args[2:])
+		RunArchive(*before, *dest)
+	case "unarchive":
+		// Restore archived months back into their data root.
+		fs := flag.NewFlagSet("unarchive", flag.ExitOnError)
+		sym := fs.String("sym", "", "only this symbol (default all)")
+		month := fs.String("month", "", "only this month, YYYY-MM (default all)")
+		fs.Parse(os.Args[2:])
+		RunUnarchive(*sym, *month)
+	case "compact":
+		// Drop superseded blob generations from every month's data file.
+		RunCompact()
 	default:
-		fmt.Println("Unknown command. Use 'test' or 'probe'")
+		fmt.Println("Unknown command. Use 'test', 'probe', 'info', 'daystats', 'diag', 'warmup', 'replay', 'trace', 'verify', 'ml-export', 'archive', 'unarchive' or 'compact'")
 	}
 }
+
+// parseTestFlags applies the optional `test` flags onto the config globals.
+func parseTestFlags(args []string) {
+	fs := flag.NewFlagSet("test", flag.ExitOnError)
+	fs.BoolVar(&TrackMAE, "mae", TrackMAE, "track maximum adverse excursion between entry and exit")
+	fs.StringVar(&ReturnDef, "returns", ReturnDef, "forward return definition: log, simple or vwap")
+	fs.IntVar(&VWAPTrades, "vwap-trades", VWAPTrades, "trades averaged at each endpoint when -returns=vwap")
+	fs.Float64Var(&DaysPerYear, "days-per-year", DaysPerYear, "trading days per year used to annualize daily-PnL Sharpe ratios")
+	fs.Float64Var(&TrainFrac, "train-frac", TrainFrac, "chronological fraction of samples in the train (IS) segment")
+	fs.IntVar(&BootstrapIters, "boot-iters", BootstrapIters, "day-block bootstrap iterations for OOS IC / breakeven bands")
+	fs.IntVar(&BootstrapBlockDays, "boot-block", BootstrapBlockDays, "bootstrap block length in days")
+	fs.StringVar(&SignalDir, "signal", SignalDir, "directory of external signal CSVs (ts_ms,signal; .csv or .csv.gz), optionally per symbol subdirectory")
+	fs.StringVar(&SignalAlign, "signal-align", SignalAlign, "external signal alignment: ffill or nearest")
+	fs.StringVar(&LogPath, "log", LogPath, "also write all report tables to this file")
+	fs.BoolVar(&UseLedger, "ledger", UseLedger, "record OOS evaluations in "+LedgerPath+" and warn on reuse")
+	fs.BoolVar(&NoOOS, "no-oos", NoOOS, "in-sample only: withhold the test segment and split the train segment instead")
+	fs.StringVar(&ExportCSVPath, "export-csv", ExportCSVPath, "write per-day stats for every model/horizon to this CSV")
+	fs.StringVar(&TimingCSVPath, "timing-csv", TimingCSVPath, "write per-day load/stream wall time and throughput to this CSV")
+	fs.BoolVar(&Verbose, "verbose", Verbose, "add detailed trade-profile sections to the report")
+	fs.BoolVar(&MakerSim, "maker", MakerSim, "simulate maker (limit) entries with partial fills")
+	fs.IntVar(&MakerFillSec, "maker-window", MakerFillSec, "seconds a maker order rests before the opportunity is skipped")
+	fs.Float64Var(&MakerFeeBps, "maker-fee", MakerFeeBps, "maker fee in bps per fill (negative for a rebate)")
+	fs.Float64Var(&ImpactCoefBps, "impact-c", ImpactCoefBps, "sqrt impact coefficient in bps (0 = off)")
+	fs.Float64Var(&ImpactNotional, "impact-notional", ImpactNotional, "order notional for the impact model, quote units")
+	fs.IntVar(&ImpactWindowSec, "impact-window", ImpactWindowSec, "seconds of dollar volume the impact model scales against")
+	fs.Float64Var(&PositionCap, "pos-cap", PositionCap, "cap |position| (signal std units) in the signal-as-position table")
+	fs.Float64Var(&PositionMaxChange, "pos-dmax", PositionMaxChange, "max |position change| per sample in the signal-as-position table")
+	fs.Float64Var(&MakerThreshold, "maker-thr", MakerThreshold, "with -maker, also report the fill rate of samples whose signal is more than this many train std from the train mean")
+	fs.Float64Var(&WinsorPct, "winsor", WinsorPct, "also report IC on returns winsorized at this train percentile (e.g. 0.01), 0 = off")
+	fs.StringVar(&PortfolioSpec, "portfolio", PortfolioSpec, "MODEL@HORIZON: inverse-vol weighted cross-symbol portfolio of that cell")
+	fs.BoolVar(&CrossSection, "xs", CrossSection, "cross-sectional rank IC across symbols per sample timestamp")
+	fs.IntVar(&CrossSectionMinSyms, "xs-min", CrossSectionMinSyms, "minimum symbols per timestamp for -xs")
+	fs.IntVar(&MinRowsPerDay, "min-rows", MinRowsPerDay, "skip days with fewer trades (0 = keep all)")
+	fs.BoolVar(&VerifyChecksums, "verify", VerifyChecksums, "check each day's blob against its index checksum, skipping mismatches")
+	fs.BoolVar(&PlanOnly, "plan", PlanOnly, "print the estimated run time and memory per symbol, then stop")
+	fs.BoolVar(&DryRun, "dry-run", DryRun, "like -plan, also listing days, models, horizons and output files; writes nothing")
+	fs.BoolVar(&FeatureStats, "feature-stats", FeatureStats, "report per-model feature mean, std, non-zero and NaN/Inf shares to catch degenerate models")
+	fs.BoolVar(&HTMLDashboard, "html", HTMLDashboard, "also write a self-contained HTML dashboard per symbol")
+	horizons := fs.String("horizons", strings.Join(HorizonLabels, ","), "comma-separated horizons, e.g. 15m,1h,5000t")
+	ladder := fs.String("imb-ladder", "", "comma-separated trade-window lengths of the Imb_L<n> imbalance models, e.g. 1,4,16,64")
+	fs.BoolVar(&SpreadProxyModel, "spread-proxy", SpreadProxyModel, "add the trade-implied spread model (Spread_Proxy)")
+	fs.BoolVar(&SizeAnomalyModel, "size-anomaly", SizeAnomalyModel, "add the signed trade-size z-score model (Size_Anomaly)")
+	fs.BoolVar(&SignRunModels, "sign-run", SignRunModels, "add the trade-sign run-length and reversal-rate models")
+	fs.BoolVar(&ElasticityAsymModel, "elast-asym", ElasticityAsymModel, "add the buy/sell price-elasticity models (Elast_Buy, Elast_Sell, Elast_Asym)")
+	fs.Float64Var(&ZVarFloor, "z-floor", ZVarFloor, "floor of EW z-score variances as a fraction of their long-run variance")
+	fs.Float64Var(&ZClamp, "z-clamp", ZClamp, "cap on |z| of EW z-score models (0 = off)")
+	oosDates := fs.String("oos-dates", "", "comma-separated extra OOS start dates (YYYY-MM-DD), each reported as its own section")
+	fs.BoolVar(&DollarVolume, "dollar", DollarVolume, "add quote-notional weighted copies (<Model>_Dollar) of the volume-driven models")
+	fs.Float64Var(&RankWeightBE, "rank-w-be", RankWeightBE, "top-table score weight of the OOS breakeven (bps)")
+	fs.Float64Var(&RankWeightICT, "rank-w-ict", RankWeightICT, "top-table score weight of the effective IC t-stat")
+	fs.Float64Var(&RankWeightRatio, "rank-w-ratio", RankWeightRatio, "top-table score weight of the OOS/IS IC ratio")
+	fs.IntVar(&TopTableRows, "top", TopTableRows, "models shown in the report's top table")
+	fs.Parse(args)
+
+	if err := setImbalanceLadder(*ladder); err != nil {
+		fmt.Println("Invalid -imb-ladder:", err)
+		os.Exit(2)
+	}
+	if DryRun {
+		PlanOnly = true
+	}
+	OOSDates = nil
+	for _, f := range strings.Split(*oosDates, ",") {
+		if f = strings.TrimSpace(f); f == "" {
+			continue
+		}
+		d, err := time.Parse("2006-01-02", f)
+		if err != nil {
+			fmt.Println("Invalid -oos-dates (want YYYY-MM-DD):", f)
+			os.Exit(2)
+		}
+		OOSDates = append(OOSDates, d)
+	}
+
+	hs, err := ParseHorizons(*horizons)
+	if err != nil {
+		fmt.Println("Invalid -horizons:", err)
+		os.Exit(2)
+	}
+	SetHorizons(hs)
+
+	switch ReturnDef {
+	case "log", "simple", "vwap":
+	default:
+		fmt.Println("Invalid -returns (use log, simple or vwap):", ReturnDef)
+		os.Exit(2)
+	}
+	if WinsorPct < 0 || WinsorPct >= 0.5 {
+		fmt.Println("Invalid -winsor (want 0 <= p < 0.5):", WinsorPct)
+		os.Exit(2)
+	}
+	if SignalAlign != "ffill" && SignalAlign != "nearest" {
+		fmt.Println("Invalid -signal-align (use ffill or nearest):", SignalAlign)
+		os.Exit(2)
+	}
+}
+
+// setImbalanceLadder parses a comma-separated list of window lengths into
+// ImbalanceLadder, dropping duplicates.
+func setImbalanceLadder(list string) error {
+	ImbalanceLadder = nil
+	seen := make(map[int]bool)
+	for _, f := range strings.Split(list, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		L, err := strconv.Atoi(f)
+		if err != nil || L < 1 {
+			return fmt.Errorf("%q is not a positive trade count", f)
+		}
+		if !seen[L] {
+			seen[L] = true
+			ImbalanceLadder = append(ImbalanceLadder, L)
+		}
+	}
+	return nil
+}
+
+// getMemUsage summarizes the Go heap for tuning the GC percent set in main:
+// live allocation, HeapSys as a peak-heap proxy (the runtime returns memory
+// to the OS only lazily), total bytes obtained from the OS and GC cycles.
+func getMemUsage() string {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return fmt.Sprintf("alloc=%dMB peak=%dMB sys=%dMB gc=%d", m.Alloc>>20, m.HeapSys>>20, m.Sys>>20, m.NumGC)
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"math"
+	"strconv"
 )
 
 // ContinuousModel defines a physics object that updates on dt/price/volume.
@@ -11,6 +12,93 @@ type ContinuousModel interface {
 	Update(dt float64, p, v float64) float64
 }
 
+// zGuard keeps EW-variance z-scores sane across quiet spells. The variance
+// is floored at ZVarFloor times a slow EW (long-run) estimate fed the same
+// squared deviations, so a dead hour cannot shrink it toward zero, and |z|
+// is capped at ZClamp. Clamps counts how often either rail fired since the
+// last Reset; RunStream reports it per day.
+type zGuard struct {
+	longVr float64
+	clamps int
+}
+
+// track feeds one squared deviation into the long-run variance; alpha is
+// the caller's own EW weight, and the long-run one is 16x slower.
+func (g *zGuard) track(sq, alpha float64) {
+	if g.longVr == 0 {
+		g.longVr = sq
+		return
+	}
+	g.longVr += alpha / 16 * (sq - g.longVr)
+}
+
+// z returns x / sqrt(vr) with the floor and cap applied.
+func (g *zGuard) z(x, vr float64) float64 {
+	const eps = 1e-12
+	clamped := false
+	if floor := ZVarFloor * g.longVr; vr < floor {
+		vr, clamped = floor, true
+	}
+	z := x / math.Sqrt(vr+eps)
+	if ZClamp > 0 && math.Abs(z) > ZClamp {
+		z, clamped = math.Copysign(ZClamp, z), true
+	}
+	if clamped {
+		g.clamps++
+	}
+	return z
+}
+
+// Clamps implements clampCounter.
+func (g *zGuard) Clamps() int { return g.clamps }
+
+// clampCounter is implemented by models that normalize through a zGuard.
+type clampCounter interface {
+	Clamps() int
+}
+
+// tickSide classifies trades by the tick rule for models that embed it:
+// an uptick is a buy, a downtick a sell and a zero tick keeps the previous
+// side (0 until the first non-zero tick).
+type tickSide struct {
+	lastP float64
+	side  float64
+	init  bool
+}
+
+// tick feeds the trade price and returns the previous one; ok is false on
+// the first trade since Reset, which has no tick to classify.
+func (t *tickSide) tick(p float64) (prev float64, ok bool) {
+	prev, ok = t.lastP, t.init
+	t.lastP, t.init = p, true
+	if !ok {
+		return prev, false
+	}
+	if p > prev {
+		t.side = 1
+	} else if p < prev {
+		t.side = -1
+	}
+	return prev, true
+}
+
+// sideAware models receive the trade's aggressor side (DayColumns.Signs:
+// +1 buyer-initiated, -1 seller-initiated) before each Update.
+type sideAware interface {
+	SetSide(sign int8)
+}
+
+// sidedModels returns the models of ms that take the aggressor side.
+func sidedModels(ms []ContinuousModel) []sideAware {
+	var out []sideAware
+	for _, m := range ms {
+		if s, ok := m.(sideAware); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
 // ============================================================================
 // 1. Baseline Hawkes_Intensity (keep as-is; this is your proven baseline)
 // ============================================================================
@@ -188,14 +276,429 @@ func (m *ModelHilbert) Update(dt float64, p, v float64) float64 {
 }
 
 // ============================================================================
-// 5. Model registry
+// 5. Spread_Proxy: buy-side vs sell-side EWMA price gap
+// ============================================================================
+
+// ModelSpreadProxy estimates the bid-ask spread from trades alone: buyer-
+// initiated trades print near the ask and seller-initiated ones near the bid,
+// so the gap between their EWMA prices, relative to the midpoint, tracks the
+// quoted spread. Side is the aggressor bit of the trade (sideAware); the
+// tick rule would misfile every zero-tick print.
+type ModelSpreadProxy struct {
+	alpha             float64 // per-trade EWMA weight
+	buyP, sellP       float64
+	haveBuy, haveSell bool
+	side              int8 // aggressor side of the next Update
+}
+
+func NewSpreadProxy() *ModelSpreadProxy {
+	// alpha=0.05 -> ~20-trade memory per side.
+	return &ModelSpreadProxy{alpha: 0.05}
+}
+
+func (m *ModelSpreadProxy) Name() string { return "Spread_Proxy" }
+
+func (m *ModelSpreadProxy) Reset() {
+	m.buyP, m.sellP, m.haveBuy, m.haveSell, m.side = 0, 0, false, false, 0
+}
+
+// SetSide implements sideAware.
+func (m *ModelSpreadProxy) SetSide(sign int8) { m.side = sign }
+
+func (m *ModelSpreadProxy) Update(dt float64, p, v float64) float64 {
+	if m.side > 0 {
+		if m.haveBuy {
+			m.buyP += m.alpha * (p - m.buyP)
+		} else {
+			m.buyP, m.haveBuy = p, true
+		}
+	} else if m.side < 0 {
+		if m.haveSell {
+			m.sellP += m.alpha * (p - m.sellP)
+		} else {
+			m.sellP, m.haveSell = p, true
+		}
+	}
+
+	if !m.haveBuy || !m.haveSell {
+		return 0
+	}
+	mid := 0.5 * (m.buyP + m.sellP)
+	if mid <= 0 {
+		return 0
+	}
+	return (m.buyP - m.sellP) / mid
+}
+
+// ============================================================================
+// 6. Size_Anomaly: signed z-score of sqrt trade size
+// ============================================================================
+
+// ModelSizeAnomaly flags unusually large trades: the z-score of sqrt(qty)
+// against an EWMA mean and variance of recent sqrt sizes, signed by the
+// tick-rule side. The square root tames crypto's heavy-tailed size
+// distribution before the moments are tracked.
+type ModelSizeAnomaly struct {
+	zGuard
+	tickSide
+	alpha    float64 // per-trade EWMA weight
+	mean, vr float64
+	warm     bool
+}
+
+func NewSizeAnomaly() *ModelSizeAnomaly {
+	// alpha=0.01 -> ~100-trade memory.
+	return &ModelSizeAnomaly{alpha: 0.01}
+}
+
+func (m *ModelSizeAnomaly) Name() string { return "Size_Anomaly" }
+
+func (m *ModelSizeAnomaly) Reset() {
+	m.mean, m.vr, m.warm = 0, 0, false
+	m.zGuard, m.tickSide = zGuard{}, tickSide{}
+}
+
+func (m *ModelSizeAnomaly) Update(dt float64, p, v float64) float64 {
+	s := math.Sqrt(math.Max(v, 0))
+	if _, ok := m.tick(p); !ok {
+		m.mean = s
+		return 0
+	}
+
+	d := s - m.mean
+	z := 0.0 // variance not yet estimated
+	if m.warm {
+		z = m.z(d, m.vr)
+	}
+	m.warm = true
+
+	m.mean += m.alpha * d
+	m.vr = (1 - m.alpha) * (m.vr + m.alpha*d*d)
+	m.track(d*d, m.alpha)
+	return m.side * z
+}
+
+// ============================================================================
+// 7. Imb_L<n>: multi-scale signed-volume imbalance ladder
+// ============================================================================
+
+// ModelImbalance is the tick-rule signed share of volume over the last L
+// trades, in [-1, 1], divided by the root of its own EW second moment so
+// every rung of ImbalanceLadder reports on a comparable scale. Rolling sums
+// over a ring of the last L trades keep each update O(1).
+type ModelImbalance struct {
+	zGuard
+	tickSide
+	L            int
+	alpha        float64 // EW weight of the variance; memory grows with L
+	signed, vols []float64
+	pos, filled  int
+	sumS, sumV   float64
+	vr           float64
+	warm         bool
+}
+
+func NewImbalance(L int) *ModelImbalance {
+	if L < 1 {
+		L = 1
+	}
+	// alpha=1/(16L) -> variance memory of ~16 windows.
+	return &ModelImbalance{
+		L:      L,
+		alpha:  1 / (16 * float64(L)),
+		signed: make([]float64, L),
+		vols:   make([]float64, L),
+	}
+}
+
+func (m *ModelImbalance) Name() string { return "Imb_L" + strconv.Itoa(m.L) }
+
+func (m *ModelImbalance) Reset() {
+	clear(m.signed)
+	clear(m.vols)
+	m.pos, m.filled, m.sumS, m.sumV, m.vr, m.warm = 0, 0, 0, 0, 0, false
+	m.zGuard, m.tickSide = zGuard{}, tickSide{}
+}
+
+func (m *ModelImbalance) Update(dt float64, p, v float64) float64 {
+	const eps = 1e-12
+	if _, ok := m.tick(p); !ok {
+		return 0
+	}
+
+	// Drop the trade leaving the window, add the new one.
+	m.sumS += m.side*v - m.signed[m.pos]
+	m.sumV += v - m.vols[m.pos]
+	m.signed[m.pos], m.vols[m.pos] = m.side*v, v
+	if m.pos++; m.pos == m.L {
+		m.pos = 0
+	}
+	if m.filled < m.L {
+		m.filled++
+		return 0
+	}
+	if m.sumV <= eps {
+		return 0
+	}
+	imb := m.sumS / m.sumV
+
+	if !m.warm {
+		m.vr, m.warm = imb*imb, true
+		m.track(imb*imb, m.alpha)
+		return 0 // variance not yet estimated
+	}
+	z := m.z(imb, m.vr)
+	m.vr += m.alpha * (imb*imb - m.vr)
+	m.track(imb*imb, m.alpha)
+	return z
+}
+
+// ============================================================================
+// 8. Elast_*: buy vs sell price elasticity
+// ============================================================================
+
+// Outputs of ModelElasticity; all three share the same per-side EWMAs.
+const (
+	ElastAsym = iota // Elast_Buy - Elast_Sell
+	ElastBuy         // buy-side elasticity over its slow reference
+	ElastSell        // sell-side elasticity over its slow reference
+)
+
+// ModelElasticity compares how far price moves per unit of buy flow and per
+// unit of sell flow. Each side accumulates |log return| and quantity of its
+// own trades in fast EWMAs; the ratio is that side's elasticity, divided by
+// a slow EW reference of itself so both sides sit near 1. Side is the
+// aggressor bit of the trade (sideAware), so zero-tick prints count as
+// flow that did not move price. Elast_Asym is buy minus sell: positive when
+// buys move price more easily than sells.
+type ModelElasticity struct {
+	kind            int
+	fast, slow      float64 // per-trade EWMA weights of the sums and references
+	lastP           float64
+	init            bool
+	side            int8 // aggressor side of the next Update
+	dpBuy, qBuy     float64
+	dpSell, qSell   float64
+	refBuy, refSell float64
+}
+
+func NewElasticity(kind int) *ModelElasticity {
+	// fast=0.02 -> ~50-trade window; slow=0.001 -> ~1000-trade reference.
+	return &ModelElasticity{kind: kind, fast: 0.02, slow: 0.001}
+}
+
+func (m *ModelElasticity) Name() string {
+	switch m.kind {
+	case ElastBuy:
+		return "Elast_Buy"
+	case ElastSell:
+		return "Elast_Sell"
+	}
+	return "Elast_Asym"
+}
+
+func (m *ModelElasticity) Reset() {
+	m.dpBuy, m.qBuy, m.dpSell, m.qSell, m.refBuy, m.refSell = 0, 0, 0, 0, 0, 0
+	m.lastP, m.init, m.side = 0, false, 0
+}
+
+// SetSide implements sideAware.
+func (m *ModelElasticity) SetSide(sign int8) { m.side = sign }
+
+func (m *ModelElasticity) Update(dt float64, p, v float64) float64 {
+	const eps = 1e-12
+	prev, ok := m.lastP, m.init
+	m.lastP, m.init = p, true
+	if !ok || prev <= 0 || p <= 0 {
+		return 0
+	}
+	dp := math.Abs(math.Log(p / prev))
+
+	switch {
+	case m.side > 0:
+		m.dpBuy += m.fast * (dp - m.dpBuy)
+		m.qBuy += m.fast * (v - m.qBuy)
+	case m.side < 0:
+		m.dpSell += m.fast * (dp - m.dpSell)
+		m.qSell += m.fast * (v - m.qSell)
+	default:
+		return 0
+	}
+	if m.qBuy <= eps || m.qSell <= eps {
+		return 0 // one side not yet seen
+	}
+
+	eBuy, eSell := m.dpBuy/m.qBuy, m.dpSell/m.qSell
+	if m.refBuy == 0 || m.refSell == 0 {
+		m.refBuy, m.refSell = eBuy, eSell
+	} else {
+		m.refBuy += m.slow * (eBuy - m.refBuy)
+		m.refSell += m.slow * (eSell - m.refSell)
+	}
+	buy, sell := eBuy/(m.refBuy+eps), eSell/(m.refSell+eps)
+	switch m.kind {
+	case ElastBuy:
+		return buy
+	case ElastSell:
+		return sell
+	}
+	return buy - sell
+}
+
+// ============================================================================
+// 9. Sign_*: run-length and reversal features of the trade-sign process
+// ============================================================================
+
+// Outputs of ModelSignRun; all three share the same O(1) per-trade state.
+const (
+	SignRunLen  = iota // side * volume of the current same-sign run
+	SignRevRate        // EW rate of sign reversals, in [0, 1]
+	SignRunZ           // side * z-score of the current run volume vs completed runs
+)
+
+// ModelSignRun tracks runs of same-sign (tick-rule) trades. A run's length
+// is its traded volume; each reversal closes the run and feeds its volume
+// into EW moments of completed runs.
+type ModelSignRun struct {
+	zGuard
+	kind     int
+	alpha    float64 // EW weight per trade (reversal rate) and per run (moments)
+	runVol   float64
+	revRate  float64
+	mean, vr float64
+	runs     int
+	tickSide // side of the current run
+}
+
+func NewSignRun(kind int) *ModelSignRun {
+	// alpha=0.01 -> ~100-trade reversal memory, ~100-run moment memory.
+	return &ModelSignRun{kind: kind, alpha: 0.01}
+}
+
+func (m *ModelSignRun) Name() string {
+	switch m.kind {
+	case SignRevRate:
+		return "Sign_RevRate"
+	case SignRunZ:
+		return "Sign_RunZ"
+	}
+	return "Sign_RunLen"
+}
+
+func (m *ModelSignRun) Reset() {
+	m.runVol, m.revRate, m.mean, m.vr, m.runs = 0, 0, 0, 0, 0
+	m.zGuard, m.tickSide = zGuard{}, tickSide{}
+}
+
+func (m *ModelSignRun) Update(dt float64, p, v float64) float64 {
+	const eps = 1e-12
+	runSide := m.side
+	if _, ok := m.tick(p); !ok {
+		return 0
+	}
+
+	// Zero ticks keep the side and so extend the current run.
+	reversal := 0.0
+	if m.side != runSide && runSide != 0 {
+		reversal = 1
+		if m.runs == 0 {
+			m.mean = m.runVol
+		} else {
+			d := m.runVol - m.mean
+			m.mean += m.alpha * d
+			m.vr = (1 - m.alpha) * (m.vr + m.alpha*d*d)
+			m.track(d*d, m.alpha)
+		}
+		m.runs++
+		m.runVol = 0
+	}
+	m.runVol += v
+	m.revRate += m.alpha * (reversal - m.revRate)
+
+	switch m.kind {
+	case SignRevRate:
+		return m.revRate
+	case SignRunZ:
+		if m.runs < 2 {
+			return 0 // run moments not yet estimated
+		}
+		return m.side * m.z(m.runVol-m.mean, m.vr)
+	}
+	return m.side * m.runVol
+}
+
+// ============================================================================
+// 10. *_Dollar: quote-notional weighted copies
+// ============================================================================
+
+// dollarModel feeds its model p*v (quote notional) instead of the base
+// quantity, so volume-driven state is comparable across price regimes (one
+// BTC in 2020 vs 2024). Everything else about the model is unchanged; note
+// that fixed magnitudes tuned on base units (e.g. log1p(v) impacts) see
+// larger inputs, while ratio-style outputs are unaffected by the scale.
+type dollarModel struct {
+	ContinuousModel
+}
+
+func (m dollarModel) Name() string { return m.ContinuousModel.Name() + "_Dollar" }
+
+func (m dollarModel) Clamps() int {
+	if c, ok := m.ContinuousModel.(clampCounter); ok {
+		return c.Clamps()
+	}
+	return 0
+}
+
+// SetSide implements sideAware for wrapped models that take the side.
+func (m dollarModel) SetSide(sign int8) {
+	if s, ok := m.ContinuousModel.(sideAware); ok {
+		s.SetSide(sign)
+	}
+}
+
+func (m dollarModel) Update(dt float64, p, v float64) float64 {
+	return m.ContinuousModel.Update(dt, p, p*v)
+}
+
+// ============================================================================
+// 11. Model registry
 // ============================================================================
 
 func GetContinuousModels() []ContinuousModel {
-	return []ContinuousModel{
+	ms := []ContinuousModel{
 		NewHawkesIntensity(), // baseline, proven positive
 		NewHawkesOFI(),       // your new OFI-based variant
 		NewSignature(),       // sign-corrected signature
 		NewHilbert(),         // robust Hilbert_Phase
 	}
+	if SpreadProxyModel {
+		ms = append(ms, NewSpreadProxy()) // trade-implied spread
+	}
+	for _, L := range ImbalanceLadder {
+		ms = append(ms, NewImbalance(L)) // one imbalance rung per scale
+	}
+	if SizeAnomalyModel {
+		ms = append(ms, NewSizeAnomaly()) // signed whale-size z-score
+	}
+	if SignRunModels {
+		ms = append(ms, NewSignRun(SignRunLen), NewSignRun(SignRevRate), NewSignRun(SignRunZ))
+	}
+	if ElasticityAsymModel {
+		// Buy vs sell price impact per unit flow, and their difference.
+		ms = append(ms, NewElasticity(ElastAsym), NewElasticity(ElastBuy), NewElasticity(ElastSell))
+	}
+	if DollarVolume {
+		// Base vs dollar pairs of every model whose state depends on v.
+		dollar := []ContinuousModel{NewHawkesIntensity(), NewHawkesOFI(), NewSignature()}
+		if SizeAnomalyModel {
+			dollar = append(dollar, NewSizeAnomaly())
+		}
+		if ElasticityAsymModel {
+			dollar = append(dollar, NewElasticity(ElastAsym), NewElasticity(ElastBuy), NewElasticity(ElastSell))
+		}
+		for _, m := range dollar {
+			ms = append(ms, dollarModel{m})
+		}
+	}
+	return ms
 }
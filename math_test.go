@@ -0,0 +1,130 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// feedElasticity warms m up on balanced flow (alternating ±1bp ticks, each
+// aggressed in its direction), then feeds n one-sided trades that each move
+// price by stepBps in direction dir.
+func feedElasticity(m *ModelElasticity, dir float64, stepBps float64, n int) float64 {
+	m.Reset()
+	p := 100.0
+	for i := 0; i < 4000; i++ {
+		if i%2 == 0 {
+			p *= 1 + 1e-4
+			m.SetSide(1)
+		} else {
+			p *= 1 - 1e-4
+			m.SetSide(-1)
+		}
+		m.Update(1, p, 1)
+	}
+	var out float64
+	for i := 0; i < n; i++ {
+		p *= 1 + dir*stepBps*1e-4
+		m.SetSide(int8(dir))
+		out = m.Update(1, p, 1)
+	}
+	return out
+}
+
+func TestElasticityAsymOneSidedFlow(t *testing.T) {
+	m := NewElasticity(ElastAsym)
+	if got := feedElasticity(m, 1, 5, 100); got <= 0 {
+		t.Errorf("one-sided buy flow moving price 5bp per trade: Elast_Asym = %v, want > 0", got)
+	}
+	if got := feedElasticity(m, -1, 5, 100); got >= 0 {
+		t.Errorf("one-sided sell flow moving price 5bp per trade: Elast_Asym = %v, want < 0", got)
+	}
+}
+
+func TestElasticitySides(t *testing.T) {
+	// One-sided buy flow raises Elast_Buy above its reference and leaves
+	// Elast_Sell where the balanced warm-up put it; Asym is their difference.
+	buy := feedElasticity(NewElasticity(ElastBuy), 1, 5, 100)
+	sell := feedElasticity(NewElasticity(ElastSell), 1, 5, 100)
+	asym := feedElasticity(NewElasticity(ElastAsym), 1, 5, 100)
+	if buy <= 1.5 {
+		t.Errorf("Elast_Buy = %v, want well above 1", buy)
+	}
+	if math.Abs(sell-1) > 0.05 {
+		t.Errorf("Elast_Sell = %v, want ~1", sell)
+	}
+	if math.Abs(asym-(buy-sell)) > 1e-12 {
+		t.Errorf("Elast_Asym = %v, want Elast_Buy - Elast_Sell = %v", asym, buy-sell)
+	}
+}
+
+func TestElasticityBalancedFlow(t *testing.T) {
+	m := NewElasticity(ElastAsym)
+	p := 100.0
+	var out float64
+	for i := 0; i < 4000; i++ {
+		if i%2 == 0 {
+			p *= 1 + 1e-4
+			m.SetSide(1)
+		} else {
+			p *= 1 - 1e-4
+			m.SetSide(-1)
+		}
+		out = m.Update(1, p, 1)
+	}
+	if out > 0.05 || out < -0.05 {
+		t.Errorf("balanced flow: Elast_Asym = %v, want ~0", out)
+	}
+}
+
+func TestElasticityNeedsBothSides(t *testing.T) {
+	m := NewElasticity(ElastAsym)
+	m.Reset()
+	p := 100.0
+	for i := 0; i < 100; i++ {
+		p *= 1 + 1e-4
+		m.SetSide(1)
+		if got := m.Update(1, p, 1); got != 0 {
+			t.Fatalf("trade %d: Elast_Asym = %v before any sell, want 0", i, got)
+		}
+	}
+}
+
+func TestElasticityUsesAggressorSide(t *testing.T) {
+	// Sells hit a flat price while buys lift it: by the tick rule every
+	// zero-tick sell would inherit the buy side, by the aggressor bit it is
+	// sell flow that moved nothing, so sells look far less elastic.
+	m := NewElasticity(ElastAsym)
+	p := 100.0
+	var out float64
+	for i := 0; i < 2000; i++ {
+		if i%2 == 0 {
+			p *= 1 + 1e-4
+			m.SetSide(1)
+		} else {
+			m.SetSide(-1)
+		}
+		out = m.Update(1, p, 1)
+	}
+	if out <= 0.5 {
+		t.Fatalf("Elast_Asym = %v, want buys clearly more elastic", out)
+	}
+}
+
+func TestSpreadProxyUsesAggressorSide(t *testing.T) {
+	// Buys print at the ask and sells at the bid with no tick between
+	// consecutive same-side prints: the tick rule would see only zero ticks
+	// after the first bounce, the aggressor bit sees every trade.
+	m := NewSpreadProxy()
+	var out float64
+	for i := 0; i < 400; i++ {
+		side, p := int8(1), 100.01
+		if i/4%2 == 1 {
+			side, p = -1, 99.99
+		}
+		m.SetSide(side)
+		out = m.Update(0.1, p, 1)
+	}
+	if want := 0.02 / 100; math.Abs(out-want) > 1e-9 {
+		t.Fatalf("Spread_Proxy = %g, want %g", out, want)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"container/heap"
+	"iter"
+)
+
+// MergeDayTrades yields the trades of several symbols' decoded days in
+// global timestamp order as (symbol index, row index into days[sym]). Ties
+// go to the lower symbol index, so the order is deterministic. Symbols
+// missing the day may be passed as nil or empty columns.
+func MergeDayTrades(days []*DayColumns) iter.Seq2[int, int] {
+	return func(yield func(int, int) bool) {
+		h := make(mergeHeap, 0, len(days))
+		for sym, cols := range days {
+			if cols != nil && cols.Count > 0 {
+				h = append(h, mergeCursor{sym: sym, t: cols.Times[0]})
+			}
+		}
+		heap.Init(&h)
+
+		for len(h) > 0 {
+			c := &h[0]
+			if !yield(c.sym, c.row) {
+				return
+			}
+			cols := days[c.sym]
+			c.row++
+			if c.row == cols.Count {
+				heap.Pop(&h)
+				continue
+			}
+			c.t = cols.Times[c.row]
+			heap.Fix(&h, 0)
+		}
+	}
+}
+
+// mergeCursor is one symbol's position in the merge.
+type mergeCursor struct {
+	sym, row int
+	t        int64
+}
+
+type mergeHeap []mergeCursor
+
+func (h mergeHeap) Len() int { return len(h) }
+
+func (h mergeHeap) Less(i, j int) bool {
+	if h[i].t != h[j].t {
+		return h[i].t < h[j].t
+	}
+	return h[i].sym < h[j].sym
+}
+
+func (h mergeHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *mergeHeap) Push(x any) { *h = append(*h, x.(mergeCursor)) }
+
+func (h *mergeHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
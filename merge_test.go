@@ -0,0 +1,76 @@
+package main
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// mergeFixture builds one day of n trades per symbol with random, sorted
+// timestamps drawn from a small range so that ties are common.
+func mergeFixture(rng *rand.Rand, counts ...int) []*DayColumns {
+	days := make([]*DayColumns, len(counts))
+	for s, n := range counts {
+		if n < 0 {
+			continue // symbol missing the day
+		}
+		c := &DayColumns{Count: n, Times: make([]int64, n), Prices: make([]float64, n), Qtys: make([]float64, n)}
+		for i := range c.Times {
+			c.Times[i] = rng.Int63n(int64(4*n + 1))
+			c.Prices[i] = float64(s)
+		}
+		sort.Slice(c.Times, func(i, j int) bool { return c.Times[i] < c.Times[j] })
+		days[s] = c
+	}
+	return days
+}
+
+func TestMergeDayTradesOrder(t *testing.T) {
+	rng := rand.New(rand.NewSource(5))
+	days := mergeFixture(rng, 500, -1, 0, 1200, 3)
+
+	seen := make([]int, len(days))
+	lastT, lastSym, total := int64(-1), -1, 0
+	for sym, row := range MergeDayTrades(days) {
+		if row != seen[sym] {
+			t.Fatalf("symbol %d yielded row %d, want %d (rows must come in order)", sym, row, seen[sym])
+		}
+		seen[sym]++
+		tm := days[sym].Times[row]
+		if tm < lastT || (tm == lastT && sym < lastSym) {
+			t.Fatalf("(%d, %d) at t=%d follows (%d) at t=%d: not in time/symbol order", sym, row, tm, lastSym, lastT)
+		}
+		lastT, lastSym = tm, sym
+		total++
+	}
+	want := 0
+	for sym, c := range days {
+		if c == nil {
+			continue
+		}
+		if seen[sym] != c.Count {
+			t.Errorf("symbol %d: %d of %d rows yielded", sym, seen[sym], c.Count)
+		}
+		want += c.Count
+	}
+	if total != want {
+		t.Fatalf("merged %d rows, want %d", total, want)
+	}
+}
+
+func TestMergeDayTradesEmptyAndBreak(t *testing.T) {
+	for range MergeDayTrades([]*DayColumns{nil, {}}) {
+		t.Fatal("no rows expected from nil and empty days")
+	}
+
+	days := mergeFixture(rand.New(rand.NewSource(6)), 100, 100)
+	n := 0
+	for range MergeDayTrades(days) {
+		if n++; n == 10 {
+			break
+		}
+	}
+	if n != 10 {
+		t.Fatalf("stopped after %d rows, want 10", n)
+	}
+}
@@ -2,7 +2,10 @@ package main
 
 import (
 	"math"
+	"math/rand/v2"
 	"sort"
+	"sync"
+	"time"
 )
 
 // Consolidated OOS statistics for a single (model, horizon) pair.
@@ -14,12 +17,31 @@ type ReportStats struct {
 	PearsonIC  float64
 	SpearmanIC float64
 
+	// IC significance. Consecutive samples overlap when the horizon exceeds
+	// the sampling interval, so the pooled t-stat uses an effective sample
+	// size; the daily t-stat measures day-to-day stability instead.
+	EffN     float64 // overlap-adjusted test sample count
+	ICTEff   float64 // pooled Pearson IC t-stat with EffN
+	DailyICT float64 // mean / SE of per-day Pearson ICs
+	ICDays   int
+
+	// Day-block bootstrap bands (5th / 95th percentile) for the pooled IC
+	// and the breakeven cost of the sign strategy (= avg trade, bps).
+	BreakevenBps float64
+	BreakevenP5  float64
+	BreakevenP95 float64
+	ICP5         float64
+	ICP95        float64
+
 	// Directional accuracy (OOS)
 	HitRate  float64 // fraction of non-zero returns where sign(signal) == sign(return)
 	HitRateZ float64 // z-score vs 50% baseline (binomial approximation)
 
 	// Conditional return curve (deciles, OOS)
 	DecileMean         []float64 // length 10, in raw return units
+	DecileStd          []float64 // length 10, within-decile return standard deviation
+	DecileSEM          []float64 // length 10, standard error of each decile mean
+	DecileHitRate      []float64 // length 10, sign hit rate within each decile
 	TopDecileRetBps    float64
 	BottomDecileRetBps float64
 	SpreadBps          float64 // TopDecile - BottomDecile (bps)
@@ -34,12 +56,23 @@ type ReportStats struct {
 	DeltaLogLoss    float64 // Baseline - Signal; >0 is better
 
 	// Economic / risk metrics for sign(signal) strategy (OOS)
-	Sharpe       float64
-	MaxDrawdown  float64
-	AvgTrade     float64
-	AvgWin       float64
-	AvgLoss      float64
-	WinLossRatio float64
+	Sharpe           float64 // per sample
+	AnnualizedSharpe float64 // daily PnL Sharpe * sqrt(DaysPerYear)
+	PSR              float64 // P(true Sharpe > 0), skew/kurtosis-adjusted
+	MaxDrawdown      float64 // peak-to-trough of cumulative trade PnL over the test segment
+	MaxDayDrawdown   float64 // worst intraday peak-to-trough, equity restarting each day
+	AvgTrade         float64
+	AvgWin           float64
+	AvgLoss          float64
+	WinLossRatio     float64
+	WinRate          float64 // fraction of trades with PnL > 0
+	ProfitFactor     float64 // gross wins / gross losses
+	MaxConsecLoss    int     // longest run of consecutive losing trades
+	MaxConsecWin     int     // longest run of consecutive winning trades
+
+	// Shape of the per-trade PnL distribution (fat-tail diagnostics).
+	PnLSkew           float64
+	PnLKurtosisExcess float64
 }
 
 // OOS rolling-window metrics on the test segment.
@@ -67,6 +100,7 @@ type RegimeMetrics struct {
 
 // internal helper for chronological train/test split
 type trainTestSplit struct {
+	TrainT []float64
 	TrainF []float64
 	TrainR []float64
 
@@ -78,7 +112,21 @@ type trainTestSplit struct {
 // AnalyzeFullSuiteOOS computes all core metrics OOS, with a single chronological
 // train/test split for a given (model, horizon) signal.
 func AnalyzeFullSuiteOOS(times, feats, returns []float64, trainFrac float64) ReportStats {
+	return analyzeSuite(splitTrainTest(times, feats, returns, trainFrac), true)
+}
+
+// AnalyzeFullSuiteIS computes the same metrics in-sample: fit and evaluation
+// both on the train segment, so TestCount equals TrainCount. The bootstrap
+// bands are not computed and stay NaN.
+func AnalyzeFullSuiteIS(times, feats, returns []float64, trainFrac float64) ReportStats {
 	s := splitTrainTest(times, feats, returns, trainFrac)
+	s.TestT, s.TestF, s.TestR = s.TrainT, s.TrainF, s.TrainR
+	return analyzeSuite(s, false)
+}
+
+// analyzeSuite fits on s's train segment and evaluates on its test segment;
+// boot adds the day-block bootstrap bands.
+func analyzeSuite(s trainTestSplit, boot bool) ReportStats {
 	trainN := len(s.TrainF)
 	testN := len(s.TestF)
 
@@ -86,6 +134,10 @@ func AnalyzeFullSuiteOOS(times, feats, returns []float64, trainFrac float64) Rep
 		TrainCount: trainN,
 		TestCount:  testN,
 		DecileMean: make([]float64, 10),
+		DecileStd:  make([]float64, 10),
+		DecileSEM:  make([]float64, 10),
+
+		DecileHitRate: make([]float64, 10),
 	}
 	if testN < 30 {
 		// Too little test data to say anything meaningful.
@@ -95,13 +147,17 @@ func AnalyzeFullSuiteOOS(times, feats, returns []float64, trainFrac float64) Rep
 	// 1. ICs (test-only)
 	stats.PearsonIC = Pearson(s.TestF, s.TestR)
 	stats.SpearmanIC = Spearman(s.TestF, s.TestR)
+	stats.EffN = EffectiveSampleSize(s.TestT, s.TestR)
+	stats.ICTEff = icTStat(stats.PearsonIC, stats.EffN)
+	stats.DailyICT, stats.ICDays = dailyICTStat(s.TestT, s.TestF, s.TestR)
 
 	// 2. Hit rate vs 50% baseline (test-only)
 	stats.HitRate, stats.HitRateZ = HitRateStats(s.TestF, s.TestR)
 
 	// 3. Conditional return curve (deciles, test-only)
-	stats.DecileMean, stats.BottomDecileRetBps, stats.TopDecileRetBps, stats.SpreadBps =
+	stats.DecileMean, stats.DecileStd, stats.DecileSEM, stats.BottomDecileRetBps, stats.TopDecileRetBps, stats.SpreadBps =
 		DecileCurve(s.TestF, s.TestR)
+	stats.DecileHitRate = DecileHitRates(s.TestF, s.TestR)
 
 	// 4. Mutual information + NMI (test-only)
 	stats.MutualInfo, stats.NormalizedMI = CalcMutualInfo(s.TestF, s.TestR, 10)
@@ -115,6 +171,22 @@ func AnalyzeFullSuiteOOS(times, feats, returns []float64, trainFrac float64) Rep
 	// 6. Sharpe + basic risk profile (test-only)
 	stats.Sharpe, stats.MaxDrawdown, stats.AvgTrade, stats.AvgWin, stats.AvgLoss, stats.WinLossRatio =
 		StrategyRiskStats(s.TestF, s.TestR)
+	stats.AnnualizedSharpe = AnnualizedDailySharpe(s.TestT, s.TestF, s.TestR)
+	stats.WinRate, stats.ProfitFactor = WinRateStats(s.TestF, s.TestR)
+	stats.MaxConsecWin, stats.MaxConsecLoss = StreakStats(s.TestF, s.TestR)
+	stats.MaxDayDrawdown = MaxDayDrawdown(s.TestT, s.TestF, s.TestR)
+	stats.BreakevenBps = stats.AvgTrade * 1e4
+	stats.PnLSkew, stats.PnLKurtosisExcess = PnLShape(s.TestF, s.TestR)
+	stats.PSR = ProbSharpeRatio(stats.Sharpe, 0, len(signTrades(s.TestF, s.TestR)), stats.PnLSkew, stats.PnLKurtosisExcess)
+
+	// 7. Day-block bootstrap bands (test-only)
+	if !boot {
+		nan := math.NaN()
+		stats.ICP5, stats.ICP95, stats.BreakevenP5, stats.BreakevenP95 = nan, nan, nan, nan
+		return stats
+	}
+	stats.ICP5, stats.ICP95, stats.BreakevenP5, stats.BreakevenP95 =
+		BootstrapDaysOOS(s.TestT, s.TestF, s.TestR, BootstrapIters, BootstrapBlockDays)
 
 	return stats
 }
@@ -170,6 +242,63 @@ func RollingWindowMetricsOOS(times, feats, returns []float64, trainFrac float64,
 	return out
 }
 
+// RollingWindowStat summarizes one trailing window of test days.
+type RollingWindowStat struct {
+	PeriodEnd string // last day of the window, YYYY-MM-DD
+	Days      int
+	MeanIC    float64 // mean of daily Pearson ICs
+	Sharpe    float64 // annualized Sharpe of daily sign-strategy PnL (DaysPerYear)
+}
+
+// RollingDayWindowsOOS slides a window of windowDays test days forward one
+// day at a time, so edge decay over the test period shows up as a trend.
+func RollingDayWindowsOOS(times, feats, returns []float64, trainFrac float64, windowDays int) []RollingWindowStat {
+	s := splitTrainTest(times, feats, returns, trainFrac)
+	var days []int64
+	var ics, pnls []float64
+	forEachDay(s.TestT, func(start, end int) {
+		if end-start < 20 {
+			return
+		}
+		var pnl float64
+		for _, t := range signTrades(s.TestF[start:end], s.TestR[start:end]) {
+			pnl += t
+		}
+		days = append(days, int64(s.TestT[start])/dayMillis)
+		ics = append(ics, Pearson(s.TestF[start:end], s.TestR[start:end]))
+		pnls = append(pnls, pnl)
+	})
+	if windowDays < 2 || len(days) < windowDays {
+		return nil
+	}
+
+	out := make([]RollingWindowStat, 0, len(days)-windowDays+1)
+	for end := windowDays; end <= len(days); end++ {
+		win := pnls[end-windowDays : end]
+		var meanIC, mean, m2 float64
+		for i := end - windowDays; i < end; i++ {
+			meanIC += ics[i]
+		}
+		for _, x := range win {
+			mean += x
+		}
+		mean /= float64(windowDays)
+		for _, x := range win {
+			m2 += (x - mean) * (x - mean)
+		}
+		st := RollingWindowStat{
+			PeriodEnd: time.UnixMilli(days[end-1] * dayMillis).UTC().Format("2006-01-02"),
+			Days:      windowDays,
+			MeanIC:    meanIC / float64(windowDays),
+		}
+		if sd := math.Sqrt(m2 / float64(windowDays-1)); sd > 0 {
+			st.Sharpe = mean / sd * math.Sqrt(DaysPerYear)
+		}
+		out = append(out, st)
+	}
+	return out
+}
+
 // VolRegimeMetricsOOS computes OOS metrics across volatility regimes
 // (low/medium/high), based on |return| within the test segment.
 func VolRegimeMetricsOOS(times, feats, returns []float64, trainFrac float64) []RegimeMetrics {
@@ -204,32 +333,34 @@ func VolRegimeMetricsOOS(times, feats, returns []float64, trainFrac float64) []R
 		}
 	}
 
-	makeRegime := func(name string, idxs []int) RegimeMetrics {
-		if len(idxs) < 20 {
-			return RegimeMetrics{Name: name, Count: len(idxs)}
-		}
-		sig := make([]float64, len(idxs))
-		ret := make([]float64, len(idxs))
-		for j, i := range idxs {
-			sig[j] = s.TestF[i]
-			ret[j] = s.TestR[i]
-		}
-		hit, _ := HitRateStats(sig, ret)
-		sh, _, _, _, _, _ := StrategyRiskStats(sig, ret)
-		return RegimeMetrics{
-			Name:       name,
-			Count:      len(idxs),
-			PearsonIC:  Pearson(sig, ret),
-			SpearmanIC: Spearman(sig, ret),
-			HitRate:    hit,
-			Sharpe:     sh,
-		}
+	return []RegimeMetrics{
+		regimeMetrics("VolLow", s.TestF, s.TestR, idxLow),
+		regimeMetrics("VolMed", s.TestF, s.TestR, idxMed),
+		regimeMetrics("VolHigh", s.TestF, s.TestR, idxHigh),
 	}
+}
 
-	return []RegimeMetrics{
-		makeRegime("VolLow", idxLow),
-		makeRegime("VolMed", idxMed),
-		makeRegime("VolHigh", idxHigh),
+// regimeMetrics evaluates the samples idxs of (feats, rets) as one regime.
+// Regimes with fewer than 20 samples only report their count.
+func regimeMetrics(name string, feats, rets []float64, idxs []int) RegimeMetrics {
+	if len(idxs) < 20 {
+		return RegimeMetrics{Name: name, Count: len(idxs)}
+	}
+	sig := make([]float64, len(idxs))
+	ret := make([]float64, len(idxs))
+	for j, i := range idxs {
+		sig[j] = feats[i]
+		ret[j] = rets[i]
+	}
+	hit, _ := HitRateStats(sig, ret)
+	sh, _, _, _, _, _ := StrategyRiskStats(sig, ret)
+	return RegimeMetrics{
+		Name:       name,
+		Count:      len(idxs),
+		PearsonIC:  Pearson(sig, ret),
+		SpearmanIC: Spearman(sig, ret),
+		HitRate:    hit,
+		Sharpe:     sh,
 	}
 }
 
@@ -258,33 +389,336 @@ func TimeOfDayRegimeMetricsOOS(times, feats, returns []float64, trainFrac float6
 		}
 	}
 
-	makeRegime := func(name string, idxs []int) RegimeMetrics {
-		if len(idxs) < 20 {
-			return RegimeMetrics{Name: name, Count: len(idxs)}
+	return []RegimeMetrics{
+		regimeMetrics("TOD_Early", s.TestF, s.TestR, earlyIdx),
+		regimeMetrics("TOD_Mid", s.TestF, s.TestR, midIdx),
+		regimeMetrics("TOD_Late", s.TestF, s.TestR, lateIdx),
+	}
+}
+
+// SignalAgeMetricsOOS computes OOS metrics bucketed by signal age: the number
+// of samples since the signal last changed sign (1-5, 6-20, 21-100, 100+).
+// Age restarts at each UTC day boundary, since models are reset per day.
+func SignalAgeMetricsOOS(times, feats, returns []float64, trainFrac float64) []RegimeMetrics {
+	s := splitTrainTest(times, feats, returns, trainFrac)
+	n := len(s.TestF)
+	if n < 60 {
+		return nil
+	}
+
+	const dayMillis = 24 * 60 * 60 * 1000.0
+
+	var idxYoung, idxMid, idxOld, idxAncient []int
+	age := 0
+	prevSign := 0
+	prevDay := -1.0
+	for i := 0; i < n; i++ {
+		sign := 0
+		if s.TestF[i] > 0 {
+			sign = 1
+		} else if s.TestF[i] < 0 {
+			sign = -1
 		}
-		sig := make([]float64, len(idxs))
-		ret := make([]float64, len(idxs))
-		for j, i := range idxs {
-			sig[j] = s.TestF[i]
-			ret[j] = s.TestR[i]
+		day := math.Floor(s.TestT[i] / dayMillis)
+		if sign != prevSign || day != prevDay {
+			age = 0
 		}
-		hit, _ := HitRateStats(sig, ret)
-		sh, _, _, _, _, _ := StrategyRiskStats(sig, ret)
-		return RegimeMetrics{
-			Name:       name,
-			Count:      len(idxs),
-			PearsonIC:  Pearson(sig, ret),
-			SpearmanIC: Spearman(sig, ret),
-			HitRate:    hit,
-			Sharpe:     sh,
+		age++
+		prevSign = sign
+		prevDay = day
+
+		switch {
+		case age <= 5:
+			idxYoung = append(idxYoung, i)
+		case age <= 20:
+			idxMid = append(idxMid, i)
+		case age <= 100:
+			idxOld = append(idxOld, i)
+		default:
+			idxAncient = append(idxAncient, i)
 		}
 	}
 
 	return []RegimeMetrics{
-		makeRegime("TOD_Early", earlyIdx),
-		makeRegime("TOD_Mid", midIdx),
-		makeRegime("TOD_Late", lateIdx),
+		regimeMetrics("Age1-5", s.TestF, s.TestR, idxYoung),
+		regimeMetrics("Age6-20", s.TestF, s.TestR, idxMid),
+		regimeMetrics("Age21-100", s.TestF, s.TestR, idxOld),
+		regimeMetrics("Age100+", s.TestF, s.TestR, idxAncient),
+	}
+}
+
+// MAEStats summarizes maximum adverse excursion (bps, positive = adverse)
+// for one side of the sign(signal) strategy.
+type MAEStats struct {
+	Count  int
+	AvgBps float64
+	P95Bps float64
+}
+
+// MAEStatsOOS splits test-segment samples by signal sign and reports the
+// average and 95th percentile adverse excursion for longs (path minimum)
+// and shorts (path maximum). All slices must already be sorted by time,
+// which holds once the containers were sorted with SortByTime.
+func MAEStatsOOS(times, feats, minRets, maxRets []float64, trainFrac float64) (long, short MAEStats) {
+	n := len(feats)
+	if n == 0 || n != len(times) || n != len(minRets) || n != len(maxRets) {
+		return long, short
+	}
+	trainN := trainCount(n, trainFrac)
+
+	var longMAE, shortMAE []float64
+	for i := trainN; i < n; i++ {
+		switch {
+		case feats[i] > 0:
+			longMAE = append(longMAE, -minRets[i]*1e4)
+		case feats[i] < 0:
+			shortMAE = append(shortMAE, maxRets[i]*1e4)
+		}
+	}
+
+	summarize := func(vals []float64) MAEStats {
+		m := MAEStats{Count: len(vals)}
+		if len(vals) == 0 {
+			return m
+		}
+		var sum float64
+		for _, v := range vals {
+			sum += v
+		}
+		m.AvgBps = sum / float64(len(vals))
+		sort.Float64s(vals)
+		m.P95Bps = vals[int(0.95*float64(len(vals)-1))]
+		return m
+	}
+	return summarize(longMAE), summarize(shortMAE)
+}
+
+// PositionStats evaluates the signal itself as the position (in units of
+// its train-segment standard deviation) rather than its sign.
+type PositionStats struct {
+	SumPnLBps float64 // sum of pos * ret, bps
+	Turnover  float64 // sum of |Δpos|, positions flattened at each day boundary
+}
+
+// PnLPerTurnBps is PnL per unit of position traded.
+func (p PositionStats) PnLPerTurnBps() float64 {
+	if p.Turnover == 0 {
+		return 0
+	}
+	return p.SumPnLBps / p.Turnover
+}
+
+// PositionPnLOOS scores the standardized signal as a position on the test
+// segment, unconstrained and after the limits: |pos| <= posCap and
+// |Δpos| <= dmax per sample (0 disables either). The change limit is
+// applied to the capped target, so the position trails large jumps.
+func PositionPnLOOS(times, feats, rets []float64, trainFrac, posCap, dmax float64) (raw, limited PositionStats) {
+	s := splitTrainTest(times, feats, rets, trainFrac)
+	if len(s.TestF) == 0 || len(s.TrainF) < 2 {
+		return raw, limited
+	}
+	sd := stdDev(s.TrainF)
+	if sd == 0 {
+		return raw, limited
+	}
+
+	forEachDay(s.TestT, func(start, end int) {
+		var prevRaw, prevLim float64
+		for i := start; i < end; i++ {
+			target := s.TestF[i] / sd
+			raw.SumPnLBps += target * s.TestR[i] * 1e4
+			raw.Turnover += math.Abs(target - prevRaw)
+			prevRaw = target
+
+			pos := target
+			if posCap > 0 {
+				pos = math.Max(-posCap, math.Min(posCap, pos))
+			}
+			if dmax > 0 {
+				pos = prevLim + math.Max(-dmax, math.Min(dmax, pos-prevLim))
+			}
+			limited.SumPnLBps += pos * s.TestR[i] * 1e4
+			limited.Turnover += math.Abs(pos - prevLim)
+			prevLim = pos
+		}
+		// Flatten overnight.
+		raw.Turnover += math.Abs(prevRaw)
+		limited.Turnover += math.Abs(prevLim)
+	})
+	return raw, limited
+}
+
+// ImpactStats is the sign strategy's average trade before and after a
+// round-trip square-root impact cost.
+type ImpactStats struct {
+	Trades     int
+	GrossBps   float64
+	CostBps    float64 // mean round-trip cost
+	CostP95Bps float64
+	NetBps     float64
+}
+
+// ImpactStatsOOS charges each test-segment trade twice its one-way impact
+// (entry and exit). Slices must be time-sorted (SortByTime).
+func ImpactStatsOOS(times, feats, rets, impactBps []float64, trainFrac float64) ImpactStats {
+	var st ImpactStats
+	n := len(feats)
+	if n == 0 || n != len(times) || n != len(rets) || n != len(impactBps) {
+		return st
+	}
+	trainN := trainCount(n, trainFrac)
+
+	var gross, cost float64
+	var costs []float64
+	for i := trainN; i < n; i++ {
+		x, r := feats[i], rets[i]
+		// Same trade set as signTrades.
+		if x == 0 || r == 0 {
+			continue
+		}
+		if x < 0 {
+			r = -r
+		}
+		c := 2 * impactBps[i]
+		gross += r * 1e4
+		cost += c
+		costs = append(costs, c)
+	}
+	st.Trades = len(costs)
+	if st.Trades == 0 {
+		return st
+	}
+	st.GrossBps = gross / float64(st.Trades)
+	st.CostBps = cost / float64(st.Trades)
+	st.NetBps = st.GrossBps - st.CostBps
+	sort.Float64s(costs)
+	st.CostP95Bps = percentileSorted(costs, 0.95)
+	return st
+}
+
+// MakerStats summarizes simulated maker entries on the test segment.
+type MakerStats struct {
+	Signals  int     // test samples with a non-zero signal
+	FillRate float64 // fraction of those whose limit order filled
+	AlphaBps float64 // mean sign-adjusted return of filled orders, before fees
+}
+
+// MakerStatsOOS picks the maker buy or sell leg by signal sign for every
+// test sample. Slices must be time-sorted (SortByTime), like MAEStatsOOS.
+func MakerStatsOOS(times, feats, buyRets, sellRets []float64, trainFrac float64) MakerStats {
+	var m MakerStats
+	n := len(feats)
+	if n == 0 || n != len(times) || n != len(buyRets) || n != len(sellRets) {
+		return m
+	}
+	trainN := trainCount(n, trainFrac)
+
+	var filled int
+	var sum float64
+	for i := trainN; i < n; i++ {
+		var r float64
+		switch {
+		case feats[i] > 0:
+			r = buyRets[i]
+		case feats[i] < 0:
+			r = -sellRets[i]
+		default:
+			continue
+		}
+		m.Signals++
+		if math.IsNaN(r) {
+			continue
+		}
+		filled++
+		sum += r
 	}
+	if m.Signals > 0 {
+		m.FillRate = float64(filled) / float64(m.Signals)
+	}
+	if filled > 0 {
+		m.AlphaBps = sum / float64(filled) * 1e4
+	}
+	return m
+}
+
+// ThresholdFillRateOOS is the maker fill rate of a threshold strategy: a
+// test sample triggers when the signal lies more than thr train-segment
+// standard deviations from the train mean, and the entry counts as attainable if a trade printed at or
+// through the trigger price within the maker window.
+func ThresholdFillRateOOS(feats, buyRets, sellRets []float64, trainFrac, thr float64) (triggered int, fillRate float64) {
+	n := len(feats)
+	if n == 0 || n != len(buyRets) || n != len(sellRets) {
+		return 0, 0
+	}
+	trainN := trainCount(n, trainFrac)
+	if trainN < 2 {
+		return 0, 0
+	}
+	var mean float64
+	for _, x := range feats[:trainN] {
+		mean += x
+	}
+	mean /= float64(trainN)
+	cut := thr * stdDev(feats[:trainN])
+
+	var filled int
+	for i := trainN; i < n; i++ {
+		var r float64
+		switch d := feats[i] - mean; {
+		case d > cut:
+			r = buyRets[i]
+		case d < -cut:
+			r = sellRets[i]
+		default:
+			continue
+		}
+		triggered++
+		if !math.IsNaN(r) {
+			filled++
+		}
+	}
+	if triggered > 0 {
+		fillRate = float64(filled) / float64(triggered)
+	}
+	return triggered, fillRate
+}
+
+// WinsorStats compares the OOS Pearson IC with the IC on winsorized returns.
+type WinsorStats struct {
+	LoBps, HiBps float64 // clip points, from the train segment's return percentiles
+	ClippedFrac  float64 // share of test returns that were clipped
+	IC           float64 // Pearson IC of test signal vs clipped test returns
+}
+
+// WinsorizedICOOS clips test returns at the pct and 1-pct percentiles of the
+// train returns (no lookahead into the test days) and recomputes the IC.
+func WinsorizedICOOS(times, feats, returns []float64, trainFrac, pct float64) WinsorStats {
+	var ws WinsorStats
+	s := splitTrainTest(times, feats, returns, trainFrac)
+	if len(s.TrainR) == 0 || len(s.TestR) == 0 {
+		return ws
+	}
+	sorted := append([]float64(nil), s.TrainR...)
+	sort.Float64s(sorted)
+	lo, hi := percentileSorted(sorted, pct), percentileSorted(sorted, 1-pct)
+	ws.LoBps, ws.HiBps = lo*1e4, hi*1e4
+
+	clipped := make([]float64, len(s.TestR))
+	var n int
+	for i, r := range s.TestR {
+		switch {
+		case r < lo:
+			r = lo
+			n++
+		case r > hi:
+			r = hi
+			n++
+		}
+		clipped[i] = r
+	}
+	ws.ClippedFrac = float64(n) / float64(len(clipped))
+	ws.IC = Pearson(s.TestF, clipped)
+	return ws
 }
 
 // ---------------------- shared train/test split ----------------------
@@ -310,29 +744,18 @@ func splitTrainTest(times, feats, returns []float64, trainFrac float64) trainTes
 	if n == 0 || n != len(returns) || n != len(times) {
 		return trainTestSplit{}
 	}
-	if trainFrac <= 0 || trainFrac >= 1 {
-		trainFrac = 0.7
-	}
 
 	// Sort all three slices chronologically by time in place.
 	sort.Sort(parallelSorter{times: times, feats: feats, rets: returns})
 
-	trainN := int(trainFrac * float64(n))
-	if trainN < 20 {
-		trainN = 20
-	}
-	if trainN > n-30 {
-		trainN = n - 30
-	}
-	if trainN <= 0 || trainN >= n {
-		trainN = n / 2
-	}
+	trainN := trainCount(n, trainFrac)
 	testN := n - trainN
 	if testN <= 0 {
 		return trainTestSplit{}
 	}
 
 	return trainTestSplit{
+		TrainT: times[:trainN],
 		TrainF: feats[:trainN],
 		TrainR: returns[:trainN],
 
@@ -342,6 +765,25 @@ func splitTrainTest(times, feats, returns []float64, trainFrac float64) trainTes
 	}
 }
 
+// trainCount returns the number of leading (chronological) samples that
+// belong to the train segment for n samples and a train fraction.
+func trainCount(n int, trainFrac float64) int {
+	if trainFrac <= 0 || trainFrac >= 1 {
+		trainFrac = 0.7
+	}
+	trainN := int(trainFrac * float64(n))
+	if trainN < 20 {
+		trainN = 20
+	}
+	if trainN > n-30 {
+		trainN = n - 30
+	}
+	if trainN <= 0 || trainN >= n {
+		trainN = n / 2
+	}
+	return trainN
+}
+
 // ---------------------- Correlation / IC ----------------------
 
 // Pearson returns the Pearson correlation coefficient between x and y.
@@ -412,6 +854,238 @@ func rankify(vals []float64) []float64 {
 	return ranks
 }
 
+// ---------------------- Per-day IC / cross-horizon consistency ----------------------
+
+// DailyICOOS returns the Pearson IC of each UTC day in the test segment,
+// keyed by day number since the Unix epoch. Days with fewer than 20 samples
+// are omitted.
+func DailyICOOS(times, feats, returns []float64, trainFrac float64) map[int64]float64 {
+	s := splitTrainTest(times, feats, returns, trainFrac)
+	n := len(s.TestF)
+	out := make(map[int64]float64)
+	if n == 0 {
+		return out
+	}
+
+	forEachDay(s.TestT, func(start, end int) {
+		if end-start >= 20 {
+			out[int64(s.TestT[start])/dayMillis] = Pearson(s.TestF[start:end], s.TestR[start:end])
+		}
+	})
+	return out
+}
+
+const dayMillis = 24 * 60 * 60 * 1000
+
+// AlphaHalfLife fits IC(h) ≈ IC0·exp(-h/τ) per day by regressing log IC on
+// the horizon lengths hs (all in one unit) and returns τ·ln2 for each day,
+// in the same unit. Only positive ICs enter a fit; a day with fewer than two
+// of them, or whose IC does not decay, gets NaN.
+func AlphaHalfLife(hs []float64, daily []map[int64]float64) map[int64]float64 {
+	out := make(map[int64]float64)
+	if len(hs) < 2 || len(daily) != len(hs) {
+		return out
+	}
+	for day := range daily[0] {
+		var xs, ys []float64
+		for i, m := range daily {
+			if ic, ok := m[day]; ok && ic > 0 {
+				xs = append(xs, hs[i])
+				ys = append(ys, math.Log(ic))
+			}
+		}
+		out[day] = math.NaN()
+		if len(xs) < 2 {
+			continue
+		}
+		if slope := olsSlope(xs, ys); slope < 0 {
+			out[day] = -math.Ln2 / slope
+		}
+	}
+	return out
+}
+
+// forEachDay calls fn with the [start, end) range of every UTC day in the
+// time-sorted slice times.
+func forEachDay(times []float64, fn func(start, end int)) {
+	n := len(times)
+	start := 0
+	for i := 1; i <= n; i++ {
+		if i < n && int64(times[i])/dayMillis == int64(times[start])/dayMillis {
+			continue
+		}
+		fn(start, i)
+		start = i
+	}
+}
+
+// EffectiveSampleSize returns n / tau, where tau = 1 + 2*sum(rho_k) is the
+// integrated autocorrelation of the returns. Only pairs within the same day
+// contribute, and the sum stops at the first non-positive lag, so
+// overlapping forward returns (horizon > sampling interval) count roughly
+// once per horizon rather than once per sample.
+func EffectiveSampleSize(times, ret []float64) float64 {
+	n := len(ret)
+	if n < 2 {
+		return float64(n)
+	}
+	var mean float64
+	for _, r := range ret {
+		mean += r
+	}
+	mean /= float64(n)
+	var c0 float64
+	for _, r := range ret {
+		d := r - mean
+		c0 += d * d
+	}
+	if c0 == 0 {
+		return float64(n)
+	}
+
+	maxLag := n / 4
+	if maxLag > 1000 {
+		maxLag = 1000
+	}
+	tau := 1.0
+	for k := 1; k <= maxLag; k++ {
+		var ck float64
+		for i := 0; i+k < n; i++ {
+			if int64(times[i])/dayMillis != int64(times[i+k])/dayMillis {
+				continue
+			}
+			ck += (ret[i] - mean) * (ret[i+k] - mean)
+		}
+		rho := ck / c0
+		if rho <= 0 {
+			break
+		}
+		tau += 2 * rho
+	}
+	return float64(n) / tau
+}
+
+// icTStat is the t-statistic of a correlation ic over n (effective) samples.
+func icTStat(ic, n float64) float64 {
+	if n <= 2 || ic*ic >= 1 {
+		return 0
+	}
+	return ic * math.Sqrt((n-2)/(1-ic*ic))
+}
+
+// dailyICTStat computes the per-day Pearson IC on time-sorted test data and
+// returns mean/SE across days together with the number of days used.
+func dailyICTStat(times, feats, ret []float64) (float64, int) {
+	var ics []float64
+	forEachDay(times, func(start, end int) {
+		if end-start >= 20 {
+			ics = append(ics, Pearson(feats[start:end], ret[start:end]))
+		}
+	})
+	d := len(ics)
+	if d < 2 {
+		return 0, d
+	}
+	var mean, m2 float64
+	for _, x := range ics {
+		mean += x
+	}
+	mean /= float64(d)
+	for _, x := range ics {
+		m2 += (x - mean) * (x - mean)
+	}
+	sd := math.Sqrt(m2 / float64(d-1))
+	if sd == 0 {
+		return 0, d
+	}
+	return mean / (sd / math.Sqrt(float64(d))), d
+}
+
+// SplitHalfStats is the in-sample split-half stability of one signal.
+type SplitHalfStats struct {
+	Days     int
+	EvenIC   float64 // pooled Pearson IC over the even rows of every day
+	OddIC    float64 // pooled Pearson IC over the odd rows of every day
+	HalfCorr float64 // correlation across days of the even vs odd daily ICs
+}
+
+// SplitHalfIS splits each train-segment day into even and odd rows and
+// measures how well the two halves agree. It never touches the test segment,
+// so it can be consulted freely as an overfitting check.
+func SplitHalfIS(times, feats, returns []float64, trainFrac float64) SplitHalfStats {
+	s := splitTrainTest(times, feats, returns, trainFrac)
+	var out SplitHalfStats
+	if len(s.TrainF) == 0 {
+		return out
+	}
+
+	n := len(s.TrainF)
+	evenF := make([]float64, 0, n/2+1)
+	evenR := make([]float64, 0, n/2+1)
+	oddF := make([]float64, 0, n/2+1)
+	oddR := make([]float64, 0, n/2+1)
+	var dayEven, dayOdd []float64
+
+	forEachDay(s.TrainT, func(start, end int) {
+		e0, o0 := len(evenF), len(oddF)
+		for i := start; i < end; i++ {
+			if (i-start)%2 == 0 {
+				evenF = append(evenF, s.TrainF[i])
+				evenR = append(evenR, s.TrainR[i])
+			} else {
+				oddF = append(oddF, s.TrainF[i])
+				oddR = append(oddR, s.TrainR[i])
+			}
+		}
+		if end-start >= 40 {
+			dayEven = append(dayEven, Pearson(evenF[e0:], evenR[e0:]))
+			dayOdd = append(dayOdd, Pearson(oddF[o0:], oddR[o0:]))
+		}
+	})
+
+	out.Days = len(dayEven)
+	out.EvenIC = Pearson(evenF, evenR)
+	out.OddIC = Pearson(oddF, oddR)
+	if out.Days >= 3 {
+		out.HalfCorr = Pearson(dayEven, dayOdd)
+	}
+	return out
+}
+
+// CrossHorizonConsistency compares two per-day IC series (e.g. the shortest
+// and longest horizon of one model) over their common days. It returns the
+// fraction of days where both ICs share a sign and the Spearman correlation
+// of the two daily IC vectors.
+func CrossHorizonConsistency(icA, icB map[int64]float64) (signAgree, rankCorr float64, days int) {
+	keys := make([]int64, 0, len(icA))
+	for d := range icA {
+		if _, ok := icB[d]; ok {
+			keys = append(keys, d)
+		}
+	}
+	days = len(keys)
+	if days == 0 {
+		return 0, 0, 0
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	a := make([]float64, days)
+	b := make([]float64, days)
+	agree := 0
+	for i, d := range keys {
+		a[i] = icA[d]
+		b[i] = icB[d]
+		if (a[i] > 0 && b[i] > 0) || (a[i] < 0 && b[i] < 0) {
+			agree++
+		}
+	}
+	signAgree = float64(agree) / float64(days)
+	if days >= 3 {
+		rankCorr = Spearman(a, b)
+	}
+	return signAgree, rankCorr, days
+}
+
 // ---------------------- Hit rate / sign accuracy ----------------------
 
 // HitRateStats computes:
@@ -456,12 +1130,13 @@ func HitRateStats(signal, ret []float64) (hitRate, z float64) {
 // Returns:
 //
 //	decMeans[10]       - average raw return per decile
+//	decSEM[10]         - standard error of each decile mean (raw units)
 //	bottomBps, topBps  - decile 0 and 9 in basis points
 //	spreadBps          - top - bottom in basis points
-func DecileCurve(signal, ret []float64) (decMeans []float64, bottomBps, topBps, spreadBps float64) {
+func DecileCurve(signal, ret []float64) (decMeans, decStd, decSEM []float64, bottomBps, topBps, spreadBps float64) {
 	n := len(signal)
 	if n == 0 || n != len(ret) {
-		return make([]float64, 10), 0, 0, 0
+		return make([]float64, 10), make([]float64, 10), make([]float64, 10), 0, 0, 0
 	}
 
 	type pair struct {
@@ -475,38 +1150,93 @@ func DecileCurve(signal, ret []float64) (decMeans []float64, bottomBps, topBps,
 	sort.Slice(data, func(i, j int) bool { return data[i].s < data[j].s })
 
 	decMeans = make([]float64, 10)
+	decStd = make([]float64, 10)
+	decSEM = make([]float64, 10)
+	sumSq := make([]float64, 10)
 	counts := make([]int, 10)
 	if n < 10 {
 		// not enough to split meaningfully
 		for i := range data {
 			decMeans[0] += data[i].r
+			sumSq[0] += data[i].r * data[i].r
 			counts[0]++
 		}
-		if counts[0] > 0 {
-			decMeans[0] /= float64(counts[0])
-		}
-		return decMeans, decMeans[0] * 1e4, decMeans[0] * 1e4, 0
-	}
-
-	for i := 0; i < n; i++ {
-		dec := int(float64(i) / float64(n) * 10.0)
-		if dec == 10 {
-			dec = 9
+	} else {
+		for i := 0; i < n; i++ {
+			dec := int(float64(i) / float64(n) * 10.0)
+			if dec == 10 {
+				dec = 9
+			}
+			decMeans[dec] += data[i].r
+			sumSq[dec] += data[i].r * data[i].r
+			counts[dec]++
 		}
-		decMeans[dec] += data[i].r
-		counts[dec]++
 	}
 	for d := 0; d < 10; d++ {
-		if counts[d] > 0 {
-			decMeans[d] /= float64(counts[d])
+		c := counts[d]
+		if c == 0 {
+			continue
+		}
+		decMeans[d] /= float64(c)
+		if c > 1 {
+			// Sample variance from the sum of squares, then SEM = sd / sqrt(n).
+			variance := (sumSq[d] - float64(c)*decMeans[d]*decMeans[d]) / float64(c-1)
+			if variance > 0 {
+				decStd[d] = math.Sqrt(variance)
+				decSEM[d] = decStd[d] / math.Sqrt(float64(c))
+			}
 		}
 	}
+	if n < 10 {
+		return decMeans, decStd, decSEM, decMeans[0] * 1e4, decMeans[0] * 1e4, 0
+	}
+
 	bottom := decMeans[0]
 	top := decMeans[9]
 	bottomBps = bottom * 1e4
 	topBps = top * 1e4
 	spreadBps = (top - bottom) * 1e4
-	return decMeans, bottomBps, topBps, spreadBps
+	return decMeans, decStd, decSEM, bottomBps, topBps, spreadBps
+}
+
+// DecileHitRates returns the directional hit rate (same rule as HitRateStats)
+// within each signal decile, using the same equal-count bins as DecileCurve.
+func DecileHitRates(signal, ret []float64) []float64 {
+	out := make([]float64, 10)
+	n := len(signal)
+	if n < 10 || n != len(ret) {
+		return out
+	}
+
+	bins := quantileBins(signal, 10)
+	var hits, trials [10]int
+	for i := 0; i < n; i++ {
+		r := ret[i]
+		s := signal[i]
+		if r == 0 || s == 0 {
+			continue
+		}
+		b := bins[i]
+		trials[b]++
+		if (r > 0 && s > 0) || (r < 0 && s < 0) {
+			hits[b]++
+		}
+	}
+	for d := 0; d < 10; d++ {
+		if trials[d] > 0 {
+			out[d] = float64(hits[d]) / float64(trials[d])
+		}
+	}
+	return out
+}
+
+// decileT returns the t-statistic of decile d's mean return against zero,
+// using the decile's standard error. Returns 0 when the SEM is unavailable.
+func decileT(stats ReportStats, d int) float64 {
+	if d < 0 || d >= len(stats.DecileSEM) || stats.DecileSEM[d] <= 0 {
+		return 0
+	}
+	return stats.DecileMean[d] / stats.DecileSEM[d]
 }
 
 // ---------------------- Mutual information ----------------------
@@ -784,28 +1514,7 @@ func fitLogistic1D(f, y []float64) (a, b float64) {
 //
 // and then Sharpe, max drawdown, and simple trade stats.
 func StrategyRiskStats(signal, ret []float64) (sharpe, maxDD, avgTrade, avgWin, avgLoss, winLoss float64) {
-	n := len(signal)
-	if n == 0 || n != len(ret) {
-		return 0, 0, 0, 0, 0, 0
-	}
-
-	var trades []float64
-	trades = trades[:0]
-
-	for i := 0; i < n; i++ {
-		s := signal[i]
-		r := ret[i]
-		if s == 0 || r == 0 {
-			continue
-		}
-		var sign float64
-		if s > 0 {
-			sign = 1
-		} else {
-			sign = -1
-		}
-		trades = append(trades, sign*r)
-	}
+	trades := signTrades(signal, ret)
 
 	m := len(trades)
 	if m == 0 {
@@ -870,3 +1579,298 @@ func StrategyRiskStats(signal, ret []float64) (sharpe, maxDD, avgTrade, avgWin,
 	// maxDrawdown is negative; return positive magnitude.
 	return sharpe, -maxDrawdown, avgTrade, avgWin, avgLoss, winLoss
 }
+
+// WinRateStats returns the share of winning sign(signal) trades and the
+// profit factor AvgWin*WinRate / (|AvgLoss|*(1-WinRate)), i.e. gross wins
+// over gross losses. Together they show whether an edge comes from
+// frequency or magnitude.
+func WinRateStats(signal, ret []float64) (winRate, profitFactor float64) {
+	trades := signTrades(signal, ret)
+	if len(trades) == 0 {
+		return 0, 0
+	}
+	var gross, loss float64
+	var wins int
+	for _, x := range trades {
+		if x > 0 {
+			gross += x
+			wins++
+		} else {
+			loss -= x
+		}
+	}
+	winRate = float64(wins) / float64(len(trades))
+	if loss > 0 {
+		profitFactor = gross / loss
+	}
+	return winRate, profitFactor
+}
+
+// signTrades returns sign(signal) * return for every sample where both the
+// signal and the return are non-zero.
+func signTrades(signal, ret []float64) []float64 {
+	n := len(signal)
+	if n == 0 || n != len(ret) {
+		return nil
+	}
+
+	var trades []float64
+	for i := 0; i < n; i++ {
+		s := signal[i]
+		r := ret[i]
+		if s == 0 || r == 0 {
+			continue
+		}
+		var sign float64
+		if s > 0 {
+			sign = 1
+		} else {
+			sign = -1
+		}
+		trades = append(trades, sign*r)
+	}
+	return trades
+}
+
+// PnLShape returns the skewness and excess kurtosis of the sign(signal)
+// strategy's per-trade PnL (population moments). Both are 0 when fewer than
+// 4 trades exist or the PnL has no dispersion.
+func PnLShape(signal, ret []float64) (skew, exKurt float64) {
+	trades := signTrades(signal, ret)
+	m := len(trades)
+	if m < 4 {
+		return 0, 0
+	}
+
+	var mean float64
+	for _, x := range trades {
+		mean += x
+	}
+	mean /= float64(m)
+
+	var m2, m3, m4 float64
+	for _, x := range trades {
+		d := x - mean
+		d2 := d * d
+		m2 += d2
+		m3 += d2 * d
+		m4 += d2 * d2
+	}
+	mf := float64(m)
+	m2 /= mf
+	m3 /= mf
+	m4 /= mf
+	if m2 <= 0 {
+		return 0, 0
+	}
+	skew = m3 / math.Pow(m2, 1.5)
+	exKurt = m4/(m2*m2) - 3
+	return skew, exKurt
+}
+
+// ProbSharpeRatio is the probabilistic Sharpe ratio (Bailey & López de
+// Prado): the probability that the true per-trade Sharpe exceeds benchmark,
+// given the observed Sharpe sr over n trades with the given skewness and
+// excess kurtosis. For normal PnL it reduces to Φ((sr-benchmark)·sqrt((n-1)/(1+sr²/2))).
+// NaN when n < 2 or the moment-adjusted variance is not positive.
+func ProbSharpeRatio(sr, benchmark float64, n int, skew, exKurt float64) float64 {
+	if n < 2 {
+		return math.NaN()
+	}
+	v := 1 - skew*sr + (exKurt+2)/4*sr*sr
+	if v <= 0 {
+		return math.NaN()
+	}
+	z := (sr - benchmark) * math.Sqrt(float64(n-1)/v)
+	return 0.5 * math.Erfc(-z/math.Sqrt2)
+}
+
+// StreakStats returns the longest runs of consecutive winning and losing
+// sign(signal) trades, in time order.
+func StreakStats(signal, ret []float64) (maxWin, maxLoss int) {
+	var win, loss int
+	for _, x := range signTrades(signal, ret) {
+		if x > 0 {
+			win++
+			loss = 0
+		} else {
+			loss++
+			win = 0
+		}
+		maxWin = max(maxWin, win)
+		maxLoss = max(maxLoss, loss)
+	}
+	return maxWin, maxLoss
+}
+
+// AnnualizedDailySharpe sums the sign-strategy PnL per UTC day and returns
+// the daily mean / std * sqrt(DaysPerYear). Unlike the per-sample Sharpe,
+// it does not count the overlapping returns of a long horizon as separate
+// periods. 0 with fewer than two days or no dispersion.
+func AnnualizedDailySharpe(times, signal, ret []float64) float64 {
+	var pnl []float64
+	forEachDay(times, func(start, end int) {
+		var sum float64
+		for _, x := range signTrades(signal[start:end], ret[start:end]) {
+			sum += x
+		}
+		pnl = append(pnl, sum)
+	})
+	sd := stdDev(pnl)
+	if sd <= 0 {
+		return 0
+	}
+	var mean float64
+	for _, x := range pnl {
+		mean += x
+	}
+	return mean / float64(len(pnl)) / sd * math.Sqrt(DaysPerYear)
+}
+
+// MaxDayDrawdown is the largest peak-to-trough decline of the cumulative
+// sign-strategy PnL within a single UTC day, in return units.
+func MaxDayDrawdown(times, signal, ret []float64) float64 {
+	var worst float64
+	forEachDay(times, func(start, end int) {
+		var equity, peak float64
+		for _, x := range signTrades(signal[start:end], ret[start:end]) {
+			equity += x
+			if equity > peak {
+				peak = equity
+			}
+			if dd := peak - equity; dd > worst {
+				worst = dd
+			}
+		}
+	})
+	return worst
+}
+
+// ---------------------- Day-block bootstrap ----------------------
+
+// dayMoments holds one test day's sufficient statistics, so a resampled
+// pooled IC and breakeven can be rebuilt without touching the samples.
+type dayMoments struct {
+	n, sx, sy, sxx, syy, sxy float64
+	pnl, trades              float64
+}
+
+func (a *dayMoments) add(b dayMoments) {
+	a.n += b.n
+	a.sx += b.sx
+	a.sy += b.sy
+	a.sxx += b.sxx
+	a.syy += b.syy
+	a.sxy += b.sxy
+	a.pnl += b.pnl
+	a.trades += b.trades
+}
+
+func (a dayMoments) pearson() float64 {
+	if a.n < 2 {
+		return 0
+	}
+	cov := a.sxy - a.sx*a.sy/a.n
+	vx := a.sxx - a.sx*a.sx/a.n
+	vy := a.syy - a.sy*a.sy/a.n
+	if vx <= 0 || vy <= 0 {
+		return 0
+	}
+	return cov / math.Sqrt(vx*vy)
+}
+
+func (a dayMoments) breakevenBps() float64 {
+	if a.trades == 0 {
+		return 0
+	}
+	return a.pnl / a.trades * 1e4
+}
+
+// BootstrapDaysOOS resamples blocks of `block` consecutive test days with
+// replacement, `iters` times, and returns the 5th/95th percentiles of the
+// pooled Pearson IC and of the sign-strategy breakeven cost (bps).
+// Iterations are split across CPUThreads goroutines; each iteration seeds
+// its own generator from its index, so the bands do not depend on the
+// worker count.
+func BootstrapDaysOOS(times, feats, ret []float64, iters, block int) (icLo, icHi, beLo, beHi float64) {
+	var days []dayMoments
+	forEachDay(times, func(start, end int) {
+		var d dayMoments
+		for i := start; i < end; i++ {
+			x, y := feats[i], ret[i]
+			d.n++
+			d.sx += x
+			d.sy += y
+			d.sxx += x * x
+			d.syy += y * y
+			d.sxy += x * y
+			// Same trade set as signTrades.
+			if y == 0 {
+				continue
+			}
+			if x > 0 {
+				d.pnl += y
+				d.trades++
+			} else if x < 0 {
+				d.pnl -= y
+				d.trades++
+			}
+		}
+		days = append(days, d)
+	})
+	nd := len(days)
+	if nd < 2 || iters <= 0 {
+		return 0, 0, 0, 0
+	}
+	if block < 1 {
+		block = 1
+	}
+	if block > nd {
+		block = nd
+	}
+	blocks := (nd + block - 1) / block
+
+	ics := make([]float64, iters)
+	bes := make([]float64, iters)
+
+	workers := CPUThreads
+	if workers > iters {
+		workers = iters
+	}
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			src := rand.NewPCG(0, 0)
+			rng := rand.New(src)
+			for it := w; it < iters; it += workers {
+				src.Seed(0x5eed, uint64(it))
+				var agg dayMoments
+				for b := 0; b < blocks; b++ {
+					start := rng.IntN(nd - block + 1)
+					for d := start; d < start+block; d++ {
+						agg.add(days[d])
+					}
+				}
+				ics[it] = agg.pearson()
+				bes[it] = agg.breakevenBps()
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	sort.Float64s(ics)
+	sort.Float64s(bes)
+	return percentileSorted(ics, 0.05), percentileSorted(ics, 0.95),
+		percentileSorted(bes, 0.05), percentileSorted(bes, 0.95)
+}
+
+// percentileSorted returns the q-quantile of sorted xs (nearest rank).
+func percentileSorted(xs []float64, q float64) float64 {
+	if len(xs) == 0 {
+		return 0
+	}
+	i := int(q * float64(len(xs)-1))
+	return xs[i]
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestProbSharpeRatioGaussian(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	const n = 5000
+	sig := make([]float64, n)
+	ret := make([]float64, n)
+	for i := range ret {
+		sig[i] = 1
+		ret[i] = 0.03 + rng.NormFloat64()
+	}
+
+	var mean, ss float64
+	for _, r := range ret {
+		mean += r
+	}
+	mean /= n
+	for _, r := range ret {
+		ss += (r - mean) * (r - mean)
+	}
+	sr := mean / math.Sqrt(ss/n)
+	skew, exKurt := PnLShape(sig, ret)
+
+	got := ProbSharpeRatio(sr, 0, n, skew, exKurt)
+	z := sr * math.Sqrt(float64(n-1)/(1+sr*sr/2))
+	want := 0.5 * math.Erfc(-z/math.Sqrt2)
+	if math.Abs(got-want) > 0.01 {
+		t.Fatalf("PSR = %.4f, closed-form normal PSR = %.4f (sr %.4f, skew %.3f, exKurt %.3f)", got, want, sr, skew, exKurt)
+	}
+
+	// With exactly normal moments the two must agree to rounding.
+	if got := ProbSharpeRatio(sr, 0, n, 0, 0); math.Abs(got-want) > 1e-12 {
+		t.Fatalf("PSR with zero skew/kurtosis = %.15f, want %.15f", got, want)
+	}
+}
+
+func TestProbSharpeRatioMomentAdjustment(t *testing.T) {
+	const sr, n = 0.1, 250
+	base := ProbSharpeRatio(sr, 0, n, 0, 0)
+	for _, tc := range []struct {
+		name         string
+		skew, exKurt float64
+		higher       bool
+	}{
+		{"negative skew", -1, 0, false},
+		{"positive skew", 1, 0, true},
+		{"fat tails", 0, 5, false},
+	} {
+		got := ProbSharpeRatio(sr, 0, n, tc.skew, tc.exKurt)
+		if math.IsNaN(got) || (got > base) != tc.higher || got == base {
+			t.Errorf("%s: PSR %.4f vs normal %.4f, want higher=%v", tc.name, got, base, tc.higher)
+		}
+	}
+}
+
+func TestProbSharpeRatioShortSample(t *testing.T) {
+	for _, n := range []int{-1, 0, 1} {
+		if got := ProbSharpeRatio(0.5, 0, n, 0, 0); !math.IsNaN(got) {
+			t.Errorf("n=%d: PSR = %v, want NaN", n, got)
+		}
+	}
+}
+
+func TestAnnualizedDailySharpeIgnoresOverlap(t *testing.T) {
+	// Every sample of a day carries the same return, as fully overlapping
+	// forward returns would: sampling 60x more densely must not inflate
+	// the annualized Sharpe.
+	rng := rand.New(rand.NewSource(2))
+	daily := make([]float64, 200)
+	for d := range daily {
+		daily[d] = 1e-3 + 1e-2*rng.NormFloat64()
+	}
+	sharpe := func(perDay int) float64 {
+		var times, sig, ret []float64
+		for d, r := range daily {
+			for k := 0; k < perDay; k++ {
+				times = append(times, float64(int64(d)*dayMillis+int64(k)*1000))
+				sig = append(sig, 1)
+				ret = append(ret, r)
+			}
+		}
+		return AnnualizedDailySharpe(times, sig, ret)
+	}
+
+	var mean float64
+	for _, r := range daily {
+		mean += r
+	}
+	mean /= float64(len(daily))
+	want := mean / stdDev(daily) * math.Sqrt(DaysPerYear)
+	for _, k := range []int{1, 60} {
+		if got := sharpe(k); math.Abs(got-want) > 1e-9 {
+			t.Errorf("%d samples/day: annualized Sharpe %.6f, want %.6f", k, got, want)
+		}
+	}
+}
+
+func TestBootstrapDaysOOSIndependentOfWorkers(t *testing.T) {
+	rng := rand.New(rand.NewSource(4))
+	var times, feats, rets []float64
+	for d := 0; d < 40; d++ {
+		for i := 0; i < 200; i++ {
+			f := rng.NormFloat64()
+			times = append(times, float64(int64(d)*dayMillis+int64(i)*1000))
+			feats = append(feats, f)
+			rets = append(rets, 0.05*f+rng.NormFloat64())
+		}
+	}
+
+	oldThreads := CPUThreads
+	t.Cleanup(func() { CPUThreads = oldThreads })
+	bands := func(workers int) [4]float64 {
+		CPUThreads = workers
+		icLo, icHi, beLo, beHi := BootstrapDaysOOS(times, feats, rets, 500, 2)
+		return [4]float64{icLo, icHi, beLo, beHi}
+	}
+	one, four := bands(1), bands(4)
+	if one != four {
+		t.Fatalf("bands with 1 worker %v, with 4 workers %v", one, four)
+	}
+	if one[0] >= one[1] || one[2] >= one[3] {
+		t.Fatalf("degenerate bands %v", one)
+	}
+}
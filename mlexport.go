@@ -0,0 +1,271 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RunMLExport writes model features on a fixed time grid, with a forward
+// return label, for training models outside this tool. Each grid point takes
+// the features after the last trade strictly before it; if that trade is
+// older than stale (or there is none), the row's features and label are
+// empty, as are non-finite features. The label is the -returns definition
+// from that trade to labelH's exit, exactly as RunStream labels samples.
+//
+// Output is one gzipped CSV per day under out/IS/<SYM>/ or out/OOS/<SYM>/,
+// split at the test report's train/test boundary (see mlExportTrainDays).
+// models is an optional comma-separated subset of model names.
+//
+// Days below MinRowsPerDay are skipped unless keepShort, in which case they
+// are written as <DAY>.short.csv.gz.
+func RunMLExport(sym, out string, interval, stale time.Duration, labelH Horizon, models string, keepShort bool) {
+	start := time.Now()
+	fmt.Println(">>> ML FEATURE EXPORT <<<")
+	if interval <= 0 {
+		fmt.Println("ml-export: -interval must be positive")
+		return
+	}
+
+	var tasks []ofiTask
+	for t := range discoverTasks(sym) {
+		tasks = append(tasks, t)
+	}
+	if len(tasks) == 0 {
+		fmt.Printf("[%s] no days found\n", sym)
+		return
+	}
+	sort.Slice(tasks, func(i, j int) bool { return taskDate(tasks[i]).Before(taskDate(tasks[j])) })
+	trainDays := mlExportTrainDays(sym, tasks)
+
+	keep, err := mlExportModelFilter(models)
+	if err != nil {
+		fmt.Println("ml-export:", err)
+		return
+	}
+	var names []string
+	for _, m := range GetContinuousModels() {
+		if keep(m.Name()) {
+			names = append(names, m.Name())
+		}
+	}
+	if len(names) == 0 {
+		fmt.Printf("ml-export: no model matches -models %q\n", models)
+		return
+	}
+	if DryRun {
+		fmt.Printf("Symbol: %s | Days: %d (IS %d) | Grid: %s | Label: %s %s | Models: %s\n",
+			sym, len(tasks), trainDays, interval, labelH, returnDefLabel(), strings.Join(names, ","))
+		for i, t := range tasks {
+			seg := "IS"
+			if i >= trainDays {
+				seg = "OOS"
+			}
+			fmt.Printf("  would write %s\n", filepath.Join(out, seg, sym, taskDate(t).Format("2006-01-02")+".csv.gz"))
+		}
+		return
+	}
+	for _, seg := range []string{"IS", "OOS"} {
+		if err := os.MkdirAll(filepath.Join(out, seg, sym), 0o755); err != nil {
+			fmt.Println("ml-export:", err)
+			return
+		}
+	}
+	fmt.Printf("Symbol: %s | Days: %d (IS %d) | Grid: %s | Stale: %s | Label: %s %s | Models: %s\n\n",
+		sym, len(tasks), trainDays, interval, stale, labelH, returnDefLabel(), strings.Join(names, ","))
+
+	type job struct {
+		task ofiTask
+		seg  string
+	}
+	jobs := make(chan job, len(tasks))
+	for i, t := range tasks {
+		seg := "IS"
+		if i >= trainDays {
+			seg = "OOS"
+		}
+		jobs <- job{t, seg}
+	}
+	close(jobs)
+
+	var mu sync.Mutex
+	var written, rows, failed int
+	var short []skippedDay
+	var wg sync.WaitGroup
+	for w := 0; w < CPUThreads; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cols := DayColumnPool.Get().(*DayColumns)
+			defer DayColumnPool.Put(cols)
+			var buf []byte
+			for j := range jobs {
+				if !LoadGNCFile(SymbolRoot(sym), sym, j.task, &buf) {
+					mu.Lock()
+					failed++
+					mu.Unlock()
+					continue
+				}
+				if _, err := InflateGNC(buf, cols); err != nil || cols.Count == 0 {
+					mu.Lock()
+					failed++
+					mu.Unlock()
+					continue
+				}
+				date := taskDate(j.task)
+				suffix := ".csv.gz"
+				if isShortDay(cols.Count) {
+					mu.Lock()
+					short = append(short, skippedDay{date, cols.Count})
+					mu.Unlock()
+					if !keepShort {
+						continue
+					}
+					suffix = ".short.csv.gz"
+				}
+				if labelH.Unit == HorizonDollar {
+					cols.FillCumNotional()
+				}
+				var ms []ContinuousModel
+				for _, m := range GetContinuousModels() {
+					if keep(m.Name()) {
+						ms = append(ms, m)
+					}
+				}
+				csv, n := mlExportDay(cols, ms, date, interval, stale, labelH)
+				path := filepath.Join(out, j.seg, sym, date.Format("2006-01-02")+suffix)
+				if err := writeFileAtomic(path, csv); err != nil {
+					fmt.Printf("[%s] %s: %v\n", sym, date.Format("2006-01-02"), err)
+					continue
+				}
+				mu.Lock()
+				written++
+				rows += n
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	printSkippedDays(sym, failed, short)
+	fmt.Printf("[%s] %d days, %d rows -> %s in %s\n", sym, written, rows, out, time.Since(start))
+}
+
+// mlExportTrainDays returns how many of the chronologically sorted tasks are
+// IS. Like the test report, it applies trainCount with TrainFrac to the
+// trades of the days that would be streamed (readable, not short); only the
+// headers are read. The day holding the boundary goes to OOS so that no
+// trade of the report's test segment is exported as IS.
+func mlExportTrainDays(sym string, tasks []ofiTask) int {
+	counts := make([]int, len(tasks))
+	total := 0
+	for i, t := range tasks {
+		n := loadBlobRows(SymbolRoot(sym), sym, t)
+		if n == 0 || isShortDay(n) {
+			continue
+		}
+		counts[i] = n
+		total += n
+	}
+	if total == 0 {
+		return 0
+	}
+	trainN := trainCount(total, TrainFrac)
+	cum := 0
+	for i, n := range counts {
+		cum += n
+		if cum > trainN {
+			return i
+		}
+	}
+	return len(tasks)
+}
+
+// mlExportModelFilter matches model names against a comma-separated list
+// (empty = all models).
+func mlExportModelFilter(list string) (func(string) bool, error) {
+	if strings.TrimSpace(list) == "" {
+		return func(string) bool { return true }, nil
+	}
+	want := make(map[string]bool)
+	for _, name := range strings.Split(list, ",") {
+		want[strings.TrimSpace(name)] = true
+	}
+	known := make(map[string]bool)
+	for _, m := range GetContinuousModels() {
+		known[m.Name()] = true
+	}
+	for name := range want {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown model %q", name)
+		}
+	}
+	return func(name string) bool { return want[name] }, nil
+}
+
+// mlExportDay streams one day through ms and returns the gzipped CSV of its
+// grid rows and the row count.
+func mlExportDay(cols *DayColumns, ms []ContinuousModel, date time.Time, interval, stale time.Duration, labelH Horizon) ([]byte, int) {
+	for _, m := range ms {
+		m.Reset()
+	}
+	feats := make([][]float64, len(ms))
+	for j := range feats {
+		feats[j] = make([]float64, 0, cols.Count)
+	}
+	streamModels(cols, ms, cols.Times[0], feats)
+
+	var raw bytes.Buffer
+	raw.WriteString("ts_ms")
+	for _, m := range ms {
+		raw.WriteString("," + m.Name())
+	}
+	raw.WriteString(",label_" + labelH.String() + "\n")
+
+	step := interval.Milliseconds()
+	dayStart := date.UnixMilli()
+	dayEnd := dayStart + dayMillis
+	staleMs := stale.Milliseconds()
+
+	rows := 0
+	last := -1 // last trade strictly before the grid point
+	for g := dayStart + step; g <= dayEnd; g += step {
+		for last+1 < cols.Count && cols.Times[last+1] < g {
+			last++
+		}
+		raw.WriteString(strconv.FormatInt(g, 10))
+		fresh := last >= 0 && (staleMs <= 0 || g-cols.Times[last] <= staleMs)
+		for j := range ms {
+			raw.WriteByte(',')
+			if !fresh {
+				continue
+			}
+			if v := feats[j][last]; !math.IsNaN(v) && !math.IsInf(v, 0) {
+				raw.WriteString(strconv.FormatFloat(v, 'g', -1, 64))
+			}
+		}
+		raw.WriteByte(',')
+		if fresh {
+			if exit := labelH.ExitIndex(cols, last); exit >= 0 {
+				if r, ok := forwardReturn(cols, last, exit); ok {
+					raw.WriteString(strconv.FormatFloat(r, 'g', -1, 64))
+				}
+			}
+		}
+		raw.WriteByte('\n')
+		rows++
+	}
+
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write(raw.Bytes())
+	zw.Close()
+	return gz.Bytes(), rows
+}
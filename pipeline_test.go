@@ -0,0 +1,236 @@
+package main
+
+import (
+	"encoding/binary"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+// fixtureSym is the symbol the synthetic fixture is stored under.
+const fixtureSym = "TESTUSDT"
+
+// encodeTBV1 builds a TBV1 blob from trade columns; buyerMaker may be nil.
+func encodeTBV1(times []int64, prices, qtys []float64, buyerMaker []bool) []byte {
+	n := len(times)
+	align := func(x int) int { return (x + CacheLine - 1) / CacheLine * CacheLine }
+	offs := make([]int, 7)
+	off := TBHdrSize
+	for i := 0; i < 6; i++ {
+		offs[i] = off
+		off = align(off + 8*n)
+	}
+	offs[6] = off
+	words := (n + 63) / 64
+	b := make([]byte, align(off+8*words))
+
+	copy(b[0:4], TBMagic)
+	binary.LittleEndian.PutUint32(b[4:8], TBVersion)
+	binary.LittleEndian.PutUint64(b[8:16], uint64(n))
+	for i, o := range offs {
+		binary.LittleEndian.PutUint32(b[16+4*i:], uint32(o))
+	}
+	for i := 0; i < n; i++ {
+		binary.LittleEndian.PutUint64(b[offs[0]+8*i:], uint64(i))
+		binary.LittleEndian.PutUint64(b[offs[1]+8*i:], math.Float64bits(prices[i]))
+		binary.LittleEndian.PutUint64(b[offs[2]+8*i:], math.Float64bits(qtys[i]))
+		binary.LittleEndian.PutUint64(b[offs[3]+8*i:], uint64(i))
+		binary.LittleEndian.PutUint64(b[offs[4]+8*i:], uint64(i))
+		binary.LittleEndian.PutUint64(b[offs[5]+8*i:], uint64(times[i]))
+		if buyerMaker != nil && buyerMaker[i] {
+			w := offs[6] + 8*(i/64)
+			binary.LittleEndian.PutUint64(b[w:], binary.LittleEndian.Uint64(b[w:])|1<<(i%64))
+		}
+	}
+	return b
+}
+
+// writeFixture stores days random-walk days of rows trades each (from
+// 2024-01-01) as fixtureSym under a temp root, routes the symbol there for
+// the rest of the test and returns its tasks in date order. Trades print
+// halfSpread (relative) above the walk for buyers and below it for sellers.
+func writeFixture(tb testing.TB, days, rows int, halfSpread float64) []ofiTask {
+	tb.Helper()
+	root := tb.TempDir()
+	dir := filepath.Join(root, fixtureSym, "2024", "01")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		tb.Fatal(err)
+	}
+
+	rng := rand.New(rand.NewSource(7))
+	var data []byte
+	var idx []idxRow
+	p := 100.0
+	for d := 1; d <= days; d++ {
+		dayStart := time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC).UnixMilli()
+		step := int64(86_400_000 / rows)
+		times := make([]int64, rows)
+		prices := make([]float64, rows)
+		qtys := make([]float64, rows)
+		bm := make([]bool, rows)
+		for i := range times {
+			times[i] = dayStart + int64(i)*step + rng.Int63n(step)
+			p *= math.Exp(2e-4 * rng.NormFloat64())
+			qtys[i] = rng.ExpFloat64()
+			bm[i] = rng.Intn(2) == 0
+			prices[i] = p * (1 + halfSpread)
+			if bm[i] {
+				prices[i] = p * (1 - halfSpread)
+			}
+		}
+		blob := encodeTBV1(times, prices, qtys, bm)
+		idx = append(idx, idxRow{Day: d, Offset: uint64(len(data)), Length: uint64(len(blob)), Checksum: blobChecksum(blob)})
+		data = append(data, blob...)
+	}
+
+	var hdr [16]byte
+	copy(hdr[0:4], IdxMagic)
+	binary.LittleEndian.PutUint32(hdr[4:8], 1)
+	if err := os.WriteFile(filepath.Join(dir, "data.quantdev"), data, 0o644); err != nil {
+		tb.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "index.quantdev"), encodeIndexFile(hdr, idx), 0o644); err != nil {
+		tb.Fatal(err)
+	}
+
+	SymbolRoots[fixtureSym] = root
+	tb.Cleanup(func() { delete(SymbolRoots, fixtureSym) })
+
+	var tasks []ofiTask
+	for t := range discoverTasks(fixtureSym) {
+		tasks = append(tasks, t)
+	}
+	sort.Slice(tasks, func(i, j int) bool { return taskDate(tasks[i]).Before(taskDate(tasks[j])) })
+	return tasks
+}
+
+// withHorizons sets the configured horizons for the rest of the test.
+func withHorizons(tb testing.TB, list string) {
+	tb.Helper()
+	hs, err := ParseHorizons(list)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	old := Horizons
+	SetHorizons(hs)
+	tb.Cleanup(func() { SetHorizons(old) })
+}
+
+// peekModel cheats: it reads the day's columns ahead of the stream and
+// outputs the forward log return over horizon plus noise, so a working
+// pipeline must find a clearly positive OOS IC. With halfSpread set it
+// peeks at the fixture's walk, stripping the bid-ask bounce off both ends.
+type peekModel struct {
+	cols       *DayColumns
+	horizon    int64 // ms
+	noise      float64
+	halfSpread float64
+	rng        *rand.Rand
+	i          int
+	t          int64
+}
+
+func (m *peekModel) Name() string      { return "Peek" }
+func (m *peekModel) Reset()            { m.i = 0 }
+func (m *peekModel) SetTime(tMs int64) { m.t = tMs }
+func (m *peekModel) Update(dt, p, v float64) float64 {
+	i := m.i
+	m.i++
+	j := sort.Search(m.cols.Count, func(k int) bool { return m.cols.Times[k] >= m.t+m.horizon })
+	if i >= m.cols.Count || j == m.cols.Count {
+		return 0
+	}
+	return math.Log(m.mid(j)/m.mid(i)) + m.noise*m.rng.NormFloat64()
+}
+
+func (m *peekModel) mid(k int) float64 {
+	return m.cols.Prices[k] / (1 + float64(m.cols.Signs[k])*m.halfSpread)
+}
+
+// loadFixtureDays runs every fixture day through LoadGNCFile, InflateGNC and
+// RunStream with ms, returning the pooled samples of horizon 0.
+func loadFixtureDays(tb testing.TB, tasks []ofiTask, cols *DayColumns, ms []ContinuousModel) (times, feats, targs []float64) {
+	tb.Helper()
+	var buf []byte
+	for _, task := range tasks {
+		if !LoadGNCFile(SymbolRoot(fixtureSym), fixtureSym, task, &buf) {
+			tb.Fatalf("LoadGNCFile %v failed", taskDate(task))
+		}
+		if _, err := InflateGNC(buf, cols); err != nil {
+			tb.Fatalf("InflateGNC %v: %v", taskDate(task), err)
+		}
+		res := RunStream(cols, ms)
+		for s, t := range res.Times {
+			times = append(times, float64(t))
+			feats = append(feats, res.Features[s*res.NumModels])
+			targs = append(targs, res.Targets[s*res.NumHorizons])
+		}
+	}
+	return times, feats, targs
+}
+
+func TestPipelinePositiveOOSIC(t *testing.T) {
+	withHorizons(t, "15m")
+	oldIters := BootstrapIters
+	BootstrapIters = 20
+	t.Cleanup(func() { BootstrapIters = oldIters })
+
+	tasks := writeFixture(t, 8, 20000, 0)
+	if len(tasks) != 8 {
+		t.Fatalf("discovered %d days, want 8", len(tasks))
+	}
+
+	cols := &DayColumns{}
+	peek := &peekModel{cols: cols, horizon: 15 * 60 * 1000, noise: 2e-3, rng: rand.New(rand.NewSource(3))}
+	times, feats, targs := loadFixtureDays(t, tasks, cols, []ContinuousModel{peek})
+	if len(times) == 0 {
+		t.Fatal("RunStream produced no samples")
+	}
+
+	stats := AnalyzeFullSuiteOOS(times, feats, targs, 0.7)
+	if stats.TestCount == 0 {
+		t.Fatal("empty OOS segment")
+	}
+	if stats.PearsonIC < 0.2 || stats.SpearmanIC < 0.2 {
+		t.Fatalf("OOS IC = %.3f (Spearman %.3f) over %d samples, want clearly positive", stats.PearsonIC, stats.SpearmanIC, stats.TestCount)
+	}
+}
+
+func TestVWAPLabelsSteadyDailyIC(t *testing.T) {
+	withHorizons(t, "15m")
+	oldDef, oldK := ReturnDef, VWAPTrades
+	VWAPTrades = 5
+	t.Cleanup(func() { ReturnDef, VWAPTrades = oldDef, oldK })
+
+	// A 10bp half spread: single-print labels carry the bounce at both
+	// ends, the 5-trade VWAPs mostly average it out.
+	const halfSpread = 1e-3
+	tasks := writeFixture(t, 12, 20000, halfSpread)
+
+	dailyICs := func(def string) (mean, variance float64) {
+		ReturnDef = def
+		cols := &DayColumns{}
+		peek := &peekModel{cols: cols, horizon: 15 * 60 * 1000, noise: 2e-3, halfSpread: halfSpread, rng: rand.New(rand.NewSource(3))}
+		times, feats, targs := loadFixtureDays(t, tasks, cols, []ContinuousModel{peek})
+		var ics []float64
+		forEachDay(times, func(start, end int) {
+			ics = append(ics, Pearson(feats[start:end], targs[start:end]))
+		})
+		for _, ic := range ics {
+			mean += ic
+		}
+		sd := stdDev(ics)
+		return mean / float64(len(ics)), sd * sd
+	}
+
+	logMean, logVar := dailyICs("log")
+	vwapMean, vwapVar := dailyICs("vwap")
+	t.Logf("per-day IC: log mean %.4f var %.2e | vwap mean %.4f var %.2e", logMean, logVar, vwapMean, vwapVar)
+	if vwapVar >= logVar {
+		t.Fatalf("per-day IC variance with vwap labels %.2e, want below log labels' %.2e", vwapVar, logVar)
+	}
+}
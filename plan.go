@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PlanCalibrationPath persists the measured streaming throughput between
+// runs so the pre-run estimate tracks this machine.
+const PlanCalibrationPath = "reports/plan_calibration.json"
+
+// tbBytesPerRow approximates a TBV1 blob's size per trade: six 8-byte
+// columns plus one buyer-maker bit (header and padding ignored).
+const tbBytesPerRow = 6*8 + 1.0/8
+
+// defaultRowModelsPerSec is the per-worker throughput assumed before any
+// run has been measured: trades x (models + 1 for labeling) per second.
+const defaultRowModelsPerSec = 20e6
+
+type planCalibration struct {
+	RowModelsPerSec float64 `json:"rowModelsPerSec"` // per worker
+	Runs            int     `json:"runs"`
+	Updated         string  `json:"updated"`
+}
+
+// studyPlan is the expected cost of one symbol's test run.
+type studyPlan struct {
+	Days      int
+	Rows      float64 // trades, from index blob sizes
+	RowModels float64 // Rows x (models + 1)
+	Wall      time.Duration
+	PeakMem   float64 // bytes
+	Calib     planCalibration
+}
+
+func loadPlanCalibration() planCalibration {
+	c := planCalibration{RowModelsPerSec: defaultRowModelsPerSec}
+	raw, err := os.ReadFile(PlanCalibrationPath)
+	if err != nil {
+		return c
+	}
+	var saved planCalibration
+	if json.Unmarshal(raw, &saved) == nil && saved.RowModelsPerSec > 0 {
+		c = saved
+	}
+	return c
+}
+
+// updatePlanCalibration folds a measured run into the stored throughput
+// (equal-weight blend with the previous value after the first run).
+func updatePlanCalibration(rowModels float64, workers int, elapsed time.Duration) error {
+	if rowModels <= 0 || workers <= 0 || elapsed <= 0 {
+		return nil
+	}
+	measured := rowModels / (elapsed.Seconds() * float64(workers))
+	c := loadPlanCalibration()
+	if c.Runs == 0 {
+		c.RowModelsPerSec = measured
+	} else {
+		c.RowModelsPerSec = 0.5*c.RowModelsPerSec + 0.5*measured
+	}
+	c.Runs++
+	c.Updated = time.Now().UTC().Format(time.RFC3339)
+
+	raw, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(PlanCalibrationPath), 0o755); err != nil {
+		return err
+	}
+	return writeFileAtomic(PlanCalibrationPath, raw)
+}
+
+// planStudy estimates work, wall time and peak memory from the index
+// metadata alone. Memory counts one blob buffer and one DayColumns per
+// worker at the largest day, plus the sample containers twice (worker
+// copies and the merged result) with every optional column enabled by the
+// current flags.
+func planStudy(tasks []ofiTask, numModels int) studyPlan {
+	p := studyPlan{Days: len(tasks), Calib: loadPlanCalibration()}
+	var maxSize uint64
+	for _, t := range tasks {
+		p.Rows += float64(t.Size) / tbBytesPerRow
+		maxSize = max(maxSize, t.Size)
+	}
+	p.RowModels = p.Rows * float64(numModels+1)
+	p.Wall = time.Duration(p.RowModels / (p.Calib.RowModelsPerSec * float64(CPUThreads)) * float64(time.Second))
+
+	maxRows := float64(maxSize) / tbBytesPerRow
+	colBytes := 24.0 // time, price, qty
+	if hasDollarHorizon(Horizons) || ImpactCoefBps > 0 {
+		colBytes += 8
+	}
+	perWorker := float64(maxSize) + maxRows*colBytes
+
+	sampleCols := 3.0 // time, feature, target
+	if TrackMAE {
+		sampleCols += 2
+	}
+	if MakerSim {
+		sampleCols += 2
+	}
+	if ImpactCoefBps > 0 {
+		sampleCols++
+	}
+	samples := float64(len(tasks)) * 86400 / SamplingRateSec
+	results := samples * float64(numModels*len(Horizons)) * sampleCols * 8 * 2
+
+	p.PeakMem = perWorker*float64(CPUThreads) + results
+	return p
+}
+
+func (p studyPlan) String() string {
+	source := "default"
+	if p.Calib.Runs > 0 {
+		source = fmt.Sprintf("calibrated over %d runs", p.Calib.Runs)
+	}
+	return fmt.Sprintf("Plan: %d days | ~%.1fM trades | est. %s at %.1fM row-models/s/worker (%s) | peak mem ~%.0f MB",
+		p.Days, p.Rows/1e6, p.Wall.Round(time.Second), p.Calib.RowModelsPerSec/1e6, source, p.PeakMem/(1<<20))
+}
+
+// printStudyDryRun lists what RunTestForSymbol would read and write for sym
+// (-dry-run). Row counts are estimated from blob sizes, as in planStudy.
+func printStudyDryRun(sym string, tasks []ofiTask, modelNames []string) {
+	byDate := append([]ofiTask(nil), tasks...)
+	sort.Slice(byDate, func(i, j int) bool { return taskDate(byDate[i]).Before(taskDate(byDate[j])) })
+
+	var short []string
+	for _, t := range byDate {
+		if isShortDay(int(float64(t.Size) / tbBytesPerRow)) {
+			short = append(short, taskDate(t).Format("2006-01-02"))
+		}
+	}
+	fmt.Printf("   Days: %d, %s .. %s", len(byDate),
+		taskDate(byDate[0]).Format("2006-01-02"), taskDate(byDate[len(byDate)-1]).Format("2006-01-02"))
+	if len(short) > 0 {
+		fmt.Printf(" (%d likely below -min-rows %d: %s)", len(short), MinRowsPerDay, strings.Join(short, " "))
+	}
+	fmt.Println()
+	fmt.Printf("   Models (%d): %s\n", len(modelNames), strings.Join(modelNames, ", "))
+	fmt.Printf("   Horizons (%d): %s | Returns: %s\n", len(HorizonLabels), strings.Join(HorizonLabels, ","), returnDefLabel())
+
+	outs := []string{
+		fmt.Sprintf("Continuous_Algo_Report_OOS_%s.txt", sym),
+		fmt.Sprintf("Continuous_Algo_Summary_%s.json", sym),
+	}
+	if HTMLDashboard {
+		outs = append(outs, fmt.Sprintf("Continuous_Algo_Dashboard_%s.html", sym))
+	}
+	if UseLedger && !NoOOS {
+		outs = append(outs, LedgerPath+" (append)")
+	}
+	outs = append(outs, PlanCalibrationPath+" (update)")
+	fmt.Printf("   Would write: %s\n", strings.Join(outs, ", "))
+}
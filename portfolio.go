@@ -0,0 +1,231 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// PortfolioPath is where -portfolio writes its cross-symbol table.
+const PortfolioPath = "Continuous_Algo_Portfolio.txt"
+
+// portfolioBook collects one (model, horizon) cell's daily signal-as-position
+// PnL from every symbol of a test run, then aggregates them into an
+// inverse-volatility weighted portfolio (-portfolio MODEL@HORIZON).
+type portfolioBook struct {
+	Model, Horizon string
+	Legs           []portfolioLeg
+}
+
+// portfolioLeg is one symbol's daily series. Positions are the signal in
+// units of its train-segment std and flat at each day end, as in the
+// signal-as-position table; RetVol is the train std of the horizon return.
+type portfolioLeg struct {
+	Symbol string
+	RetVol float64
+	Days   map[int64]portfolioDay
+}
+
+type portfolioDay struct {
+	PnL      float64 // sum of pos * ret, return units
+	Turnover float64 // sum of |Δpos|, including the flatten at day end
+	OOS      bool    // day starts at or after the symbol's train/test split
+}
+
+// newPortfolioBook parses "MODEL@HORIZON".
+func newPortfolioBook(spec string) (*portfolioBook, error) {
+	model, horizon, ok := strings.Cut(spec, "@")
+	if !ok || model == "" || horizon == "" {
+		return nil, fmt.Errorf("want MODEL@HORIZON, got %q", spec)
+	}
+	h, err := ParseHorizon(horizon)
+	if err != nil {
+		return nil, err
+	}
+	return &portfolioBook{Model: model, Horizon: h.String()}, nil
+}
+
+// AddSymbol records sym's leg from its sorted result containers. Symbols
+// without the requested cell, or whose train segment is too short or flat
+// to scale by, are skipped with a warning.
+func (b *portfolioBook) AddSymbol(sym string, modelNames []string, results [][]*ResultContainer, trainFrac float64) {
+	mIdx, hIdx := -1, -1
+	for i, name := range modelNames {
+		if name == b.Model {
+			mIdx = i
+		}
+	}
+	for i, label := range HorizonLabels {
+		if label == b.Horizon {
+			hIdx = i
+		}
+	}
+	if mIdx < 0 || hIdx < 0 {
+		fmt.Printf("[%s] WARN: portfolio cell %s@%s not in this run, symbol left out\n", sym, b.Model, b.Horizon)
+		return
+	}
+
+	rc := results[hIdx][mIdx]
+	n := len(rc.Times)
+	trainN := trainCount(n, trainFrac)
+	if trainN < 2 {
+		fmt.Printf("[%s] WARN: portfolio cell %s@%s has %d train samples, symbol left out\n", sym, b.Model, b.Horizon, trainN)
+		return
+	}
+	sigSD, retSD := stdDev(rc.Feats[:trainN]), stdDev(rc.Targs[:trainN])
+	if sigSD == 0 || retSD == 0 {
+		fmt.Printf("[%s] WARN: portfolio cell %s@%s has zero train signal or return std, symbol left out\n", sym, b.Model, b.Horizon)
+		return
+	}
+
+	leg := portfolioLeg{Symbol: sym, RetVol: retSD, Days: make(map[int64]portfolioDay)}
+	forEachDay(rc.Times, func(start, end int) {
+		var d portfolioDay
+		var prev float64
+		for i := start; i < end; i++ {
+			pos := rc.Feats[i] / sigSD
+			d.PnL += pos * rc.Targs[i]
+			d.Turnover += math.Abs(pos - prev)
+			prev = pos
+		}
+		d.Turnover += math.Abs(prev)
+		d.OOS = start >= trainN
+		leg.Days[int64(rc.Times[start])/dayMillis] = d
+	})
+	b.Legs = append(b.Legs, leg)
+}
+
+// portfolioLine is one row of the portfolio table.
+type portfolioLine struct {
+	Days                  int
+	AnnSharpe             float64 // daily PnL mean/std * sqrt(DaysPerYear)
+	TurnoverDay, MaxDDBps float64
+}
+
+func portfolioStats(pnl, turnover []float64) portfolioLine {
+	l := portfolioLine{Days: len(pnl)}
+	if len(pnl) == 0 {
+		return l
+	}
+	var mean, equity, peak float64
+	for i, x := range pnl {
+		mean += x
+		l.TurnoverDay += turnover[i]
+		equity += x
+		peak = math.Max(peak, equity)
+		l.MaxDDBps = math.Max(l.MaxDDBps, (peak-equity)*1e4)
+	}
+	mean /= float64(len(pnl))
+	l.TurnoverDay /= float64(len(pnl))
+	if sd := stdDev(pnl); sd > 0 {
+		l.AnnSharpe = mean / sd * math.Sqrt(DaysPerYear)
+	}
+	return l
+}
+
+// WriteReport aggregates the legs per segment: weights are 1/RetVol
+// normalized to sum to one, and a symbol missing a day contributes zero.
+// DivBenefit is the portfolio Sharpe over the mean single-symbol Sharpe.
+func (b *portfolioBook) WriteReport(path string) error {
+	if len(b.Legs) == 0 {
+		return fmt.Errorf("no symbol had %s@%s", b.Model, b.Horizon)
+	}
+	var invSum float64
+	for _, leg := range b.Legs {
+		invSum += 1 / leg.RetVol
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(f, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "# Portfolio: %s @ %s | Symbols: %d | Returns: %s | %s\n", b.Model, b.Horizon, len(b.Legs), returnDefLabel(), time.Now().UTC().Format(time.RFC3339))
+	fmt.Fprintf(w, "# Signal as position (train-std units, flat at day end), legs weighted by inverse train return vol; Sharpe from daily PnL x sqrt(%g)\n\n", DaysPerYear)
+
+	for _, oos := range []bool{false, true} {
+		segment := "IS"
+		if oos {
+			segment = "OOS"
+		}
+		fmt.Fprintf(w, "# %s\n", segment)
+		fmt.Fprintf(w, "LEG\tWeight\tDays\tAnnSharpe\tTurnover/day\tMaxDD(bps)\n")
+		fmt.Fprintf(w, "---\t------\t----\t---------\t------------\t----------\n")
+
+		dayPnL := make(map[int64]float64)
+		dayTurn := make(map[int64]float64)
+		var sharpeSum float64
+		for _, leg := range b.Legs {
+			weight := (1 / leg.RetVol) / invSum
+			var days []int64
+			for d, v := range leg.Days {
+				if v.OOS == oos {
+					days = append(days, d)
+				}
+			}
+			series := sortedDaySeries(leg.Days, days)
+			pnl, turn := series[0], series[1]
+			for i, d := range days {
+				dayPnL[d] += weight * pnl[i]
+				dayTurn[d] += weight * turn[i]
+			}
+			l := portfolioStats(pnl, turn)
+			sharpeSum += l.AnnSharpe
+			fmt.Fprintf(w, "%s\t%.3f\t%d\t%.2f\t%.1f\t%.1f\n", leg.Symbol, weight, l.Days, l.AnnSharpe, l.TurnoverDay, l.MaxDDBps)
+		}
+
+		var days []int64
+		for d := range dayPnL {
+			days = append(days, d)
+		}
+		sort.Slice(days, func(i, j int) bool { return days[i] < days[j] })
+		pnl := make([]float64, len(days))
+		turn := make([]float64, len(days))
+		for i, d := range days {
+			pnl[i], turn[i] = dayPnL[d], dayTurn[d]
+		}
+		l := portfolioStats(pnl, turn)
+		fmt.Fprintf(w, "PORTFOLIO\t1.000\t%d\t%.2f\t%.1f\t%.1f\n", l.Days, l.AnnSharpe, l.TurnoverDay, l.MaxDDBps)
+		if avg := sharpeSum / float64(len(b.Legs)); avg != 0 {
+			fmt.Fprintf(w, "DivBenefit\t\t\t%.2fx\t\t\n", l.AnnSharpe/avg)
+		}
+		fmt.Fprintf(w, "\n")
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// sortedDaySeries sorts days in place and returns the matching PnL and
+// turnover series.
+func sortedDaySeries(m map[int64]portfolioDay, days []int64) [2][]float64 {
+	sort.Slice(days, func(i, j int) bool { return days[i] < days[j] })
+	var out [2][]float64
+	for _, d := range days {
+		out[0] = append(out[0], m[d].PnL)
+		out[1] = append(out[1], m[d].Turnover)
+	}
+	return out
+}
+
+// stdDev is the sample standard deviation (0 for fewer than two values).
+func stdDev(xs []float64) float64 {
+	if len(xs) < 2 {
+		return 0
+	}
+	var mean, m2 float64
+	for _, x := range xs {
+		mean += x
+	}
+	mean /= float64(len(xs))
+	for _, x := range xs {
+		m2 += (x - mean) * (x - mean)
+	}
+	return math.Sqrt(m2 / float64(len(xs)-1))
+}
@@ -15,7 +15,7 @@ func RunProbe() {
 	start := time.Now()
 
 	fmt.Println(">>> GNC DATA PROBE <<<")
-	fmt.Printf("BaseDir: %s\n\n", BaseDir)
+	fmt.Printf("BaseDir: %s (+%d routed symbols)\n\n", BaseDir, len(SymbolRoots))
 
 	// Discover symbols from filesystem.
 	var symbols []string
@@ -29,8 +29,8 @@ func RunProbe() {
 	sort.Strings(symbols)
 
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "SYMBOL\tIDX_DAYS\tSAMPLED\tOK\tFAIL\tFIRST_DAY\tLAST_DAY\tMIN_ROWS\tMAX_ROWS\tAVG_ROWS")
-	fmt.Fprintln(w, "------\t--------\t-------\t--\t----\t---------\t--------\t--------\t--------\t--------")
+	fmt.Fprintln(w, "SYMBOL\tIDX_DAYS\tSAMPLED\tOK\tFAIL\tFIRST_DAY\tLAST_DAY\tMIN_ROWS\tMAX_ROWS\tAVG_ROWS\tROOT")
+	fmt.Fprintln(w, "------\t--------\t-------\t--\t----\t---------\t--------\t--------\t--------\t--------\t----")
 
 	const samplePerSymbol = 16
 
@@ -41,7 +41,7 @@ func RunProbe() {
 			tasks = append(tasks, t)
 		}
 		if len(tasks) == 0 {
-			fmt.Fprintf(w, "%-8s\t0\t0\t0\t0\t-\t-\t0\t0\t0\n", sym)
+			fmt.Fprintf(w, "%-8s\t0\t0\t0\t0\t-\t-\t0\t0\t0\t%s\n", sym, SymbolRoot(sym))
 			continue
 		}
 
@@ -94,7 +94,7 @@ func RunProbe() {
 		for _, idx := range sampleIdxs {
 			t := tasks[idx]
 
-			if !LoadGNCFile(BaseDir, sym, t, &buf) {
+			if !LoadGNCFile(SymbolRoot(sym), sym, t, &buf) {
 				failCount++
 				fmt.Printf(
 					"  [%s] %04d-%02d-%02d  STATUS=LOAD_FAIL   rows=0 reason=missing_or_unreadable_blob\n",
@@ -138,7 +138,7 @@ func RunProbe() {
 
 		fmt.Fprintf(
 			w,
-			"%-8s\t%d\t%d\t%d\t%d\t%s\t%s\t%d\t%d\t%d\n",
+			"%-8s\t%d\t%d\t%d\t%d\t%s\t%s\t%d\t%d\t%d\t%s\n",
 			sym,
 			idxDays,
 			sampled,
@@ -149,6 +149,7 @@ func RunProbe() {
 			minRows,
 			maxRows,
 			avgRows,
+			SymbolRoot(sym),
 		)
 	}
 
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+// rankRow is one model's line of the top table: its best horizon by OOS
+// breakeven cost and the stats at that horizon.
+type rankRow struct {
+	Model        string  `json:"model"`
+	BestHorizon  string  `json:"bestHorizon"`
+	Horizons     int     `json:"horizons"` // horizons with test samples
+	BreakevenBps float64 `json:"breakevenBps"`
+	OOSIC        float64 `json:"oosIC"`
+	ICT          float64 `json:"icT"`
+	ISIC         float64 `json:"isIC"`
+	OOSISRatio   float64 `json:"oosIsRatio"` // OOS IC / IS IC, clipped to ±rankRatioCap
+	Score        float64 `json:"score"`
+}
+
+// rankRatioCap bounds OOS/IS IC ratios, which explode when the IS IC is ~0.
+const rankRatioCap = 2.0
+
+// rankModels builds the top table, best score first. Horizons without test
+// samples are skipped; a model with none is left out.
+func rankModels(modelNames []string, allStats [][]ReportStats, results [][]*ResultContainer, trainFrac float64) []rankRow {
+	var rows []rankRow
+	for mIdx, name := range modelNames {
+		best, have := -1, 0
+		for hIdx := range HorizonLabels {
+			st := allStats[mIdx][hIdx]
+			if st.TestCount == 0 || math.IsNaN(st.BreakevenBps) {
+				continue
+			}
+			have++
+			if best < 0 || st.BreakevenBps > allStats[mIdx][best].BreakevenBps {
+				best = hIdx
+			}
+		}
+		if best < 0 {
+			continue
+		}
+		st := allStats[mIdx][best]
+		data := results[best][mIdx]
+		s := splitTrainTest(data.Times, data.Feats, data.Targs, trainFrac)
+		isIC := Pearson(s.TrainF, s.TrainR)
+
+		ratio := 0.0
+		if isIC != 0 && !math.IsNaN(isIC) {
+			ratio = math.Max(-rankRatioCap, math.Min(rankRatioCap, st.PearsonIC/isIC))
+		}
+		rows = append(rows, rankRow{
+			Model:        name,
+			BestHorizon:  HorizonLabels[best],
+			Horizons:     have,
+			BreakevenBps: st.BreakevenBps,
+			OOSIC:        st.PearsonIC,
+			ICT:          st.ICTEff,
+			ISIC:         isIC,
+			OOSISRatio:   ratio,
+			Score:        RankWeightBE*st.BreakevenBps + RankWeightICT*st.ICTEff + RankWeightRatio*ratio,
+		})
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].Score > rows[j].Score })
+	return rows
+}
+
+// writeTopTable prints the first limit rows of the ranking.
+func writeTopTable(w io.Writer, rows []rankRow, limit int) {
+	fmt.Fprintf(w, "\n\n# Top table: best horizon per model by OOS BE(bps); Score = %g*BE + %g*IC_T + %g*OOS/IS (ratio clipped to ±%g); top %d of %d\n",
+		RankWeightBE, RankWeightICT, RankWeightRatio, rankRatioCap, min(limit, len(rows)), len(rows))
+	fmt.Fprintf(w, "RANK\tMODEL\tBEST\tHorizons\tBE(bps)\tOOS_IC\tIC_T(eff)\tIS_IC\tOOS/IS\tScore\n")
+	fmt.Fprintf(w, "----\t-----\t----\t--------\t-------\t------\t---------\t-----\t------\t-----\n")
+	for i, r := range rows {
+		if i == limit {
+			break
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%d/%d\t%+.2f\t%.4f\t%.2f\t%.4f\t%+.2f\t%.3f\n",
+			i+1, r.Model, r.BestHorizon, r.Horizons, len(HorizonLabels),
+			r.BreakevenBps, r.OOSIC, r.ICT, r.ISIC, r.OOSISRatio, r.Score)
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// RunReplay streams one day through the selected models in a single
+// goroutine and writes one CSV row per trade: ts_ms, price, qty, sign and
+// every model's raw output (no zeroing of non-finite values). sign is the
+// taker side from the blob's buyer-maker bit (+1 buy, -1 sell). Each
+// model's internal state after the last trade is printed to stdout.
+func RunReplay(sym, day, models, out string) {
+	fmt.Println(">>> SINGLE-DAY REPLAY <<<")
+
+	keep, err := mlExportModelFilter(models)
+	if err != nil {
+		fmt.Println("replay:", err)
+		return
+	}
+	var ms []ContinuousModel
+	for _, m := range GetContinuousModels() {
+		if keep(m.Name()) {
+			m.Reset()
+			ms = append(ms, m)
+		}
+	}
+
+	cols, err := loadReplayDay(sym, day)
+	if err != nil {
+		fmt.Printf("[%s] %v\n", sym, err)
+		return
+	}
+	if out == "" {
+		out = fmt.Sprintf("replay_%s_%s.csv", sym, day)
+	}
+
+	f, err := os.Create(out)
+	if err != nil {
+		fmt.Println("replay:", err)
+		return
+	}
+	defer f.Close()
+	bw := bufio.NewWriterSize(f, 1<<20)
+	cw := csv.NewWriter(bw)
+
+	header := []string{"ts_ms", "price", "qty", "sign"}
+	for _, m := range ms {
+		header = append(header, m.Name())
+	}
+	cw.Write(header)
+
+	feats := make([][]float64, len(ms))
+	for j := range feats {
+		feats[j] = make([]float64, 0, cols.Count)
+	}
+	streamModels(cols, ms, cols.Times[0], feats)
+
+	row := make([]string, len(header))
+	for i := 0; i < cols.Count; i++ {
+		row[0] = strconv.FormatInt(cols.Times[i], 10)
+		row[1] = strconv.FormatFloat(cols.Prices[i], 'g', -1, 64)
+		row[2] = strconv.FormatFloat(cols.Qtys[i], 'g', -1, 64)
+		row[3] = strconv.Itoa(int(cols.Signs[i]))
+		for j := range ms {
+			row[4+j] = strconv.FormatFloat(feats[j][i], 'g', -1, 64)
+		}
+		cw.Write(row)
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		fmt.Println("replay:", err)
+		return
+	}
+	if err := bw.Flush(); err != nil {
+		fmt.Println("replay:", err)
+		return
+	}
+
+	fmt.Printf("Symbol: %s | Day: %s | Trades: %d | Models: %d -> %s\n\n", sym, day, cols.Count, len(ms), out)
+	fmt.Println("Model state after the last trade:")
+	for _, m := range ms {
+		fmt.Printf("  %-24s %+v\n", m.Name(), modelState(m))
+	}
+}
+
+// loadReplayDay decodes sym's day (YYYY-MM-DD) into fresh columns.
+func loadReplayDay(sym, day string) (*DayColumns, error) {
+	d, err := time.Parse("2006-01-02", day)
+	if err != nil {
+		return nil, fmt.Errorf("invalid day %q (want YYYY-MM-DD)", day)
+	}
+	for t := range discoverTasks(sym) {
+		if !taskDate(t).Equal(d) {
+			continue
+		}
+		var buf []byte
+		if !LoadGNCFile(SymbolRoot(sym), sym, t, &buf) {
+			return nil, fmt.Errorf("could not load %s", day)
+		}
+		tb, err := mapTradeBlock(buf)
+		if err != nil {
+			return nil, fmt.Errorf("could not decode %s: %v", day, err)
+		}
+		cols := &DayColumns{}
+		cols.FillFromTradeBlock(tb)
+		if cols.Count == 0 {
+			return nil, fmt.Errorf("%s has no trades", day)
+		}
+		return cols, nil
+	}
+	return nil, fmt.Errorf("%s not found in the index", day)
+}
+
+// modelState returns the value whose fields describe m's internal state,
+// unwrapping dollar-weighted copies.
+func modelState(m ContinuousModel) any {
+	if d, ok := m.(dollarModel); ok {
+		return d.ContinuousModel
+	}
+	return m
+}
@@ -0,0 +1,358 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// SignalSeries is an externally generated signal (e.g. a Python prototype)
+// loaded from CSV rows of "ts_ms,signal", sorted by timestamp.
+type SignalSeries struct {
+	Name string
+	Ts   []int64
+	Vals []float64
+}
+
+// LoadSignalSeries reads every *.csv and *.csv.gz under dir/<sym>/ (or dir/
+// when there is no per-symbol subdirectory) into one time-sorted series. A
+// non-numeric first line is treated as a header; later duplicate timestamps win.
+func LoadSignalSeries(dir, sym string) (*SignalSeries, error) {
+	src := filepath.Join(dir, sym)
+	if fi, err := os.Stat(src); err != nil || !fi.IsDir() {
+		src = dir
+	}
+	var files []string
+	for _, pattern := range []string{"*.csv", "*.csv.gz"} {
+		m, err := filepath.Glob(filepath.Join(src, pattern))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, m...)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no .csv or .csv.gz files in %s", src)
+	}
+	sort.Strings(files)
+
+	type row struct {
+		t int64
+		v float64
+	}
+	var rows []row
+	for _, path := range files {
+		data, err := readSignalFile(path)
+		if err != nil {
+			return nil, err
+		}
+		lineNo := 0
+		for len(data) > 0 {
+			var line []byte
+			if i := bytes.IndexByte(data, '\n'); i >= 0 {
+				line, data = data[:i], data[i+1:]
+			} else {
+				line, data = data, nil
+			}
+			lineNo++
+			if n := len(line); n > 0 && line[n-1] == '\r' {
+				line = line[:n-1]
+			}
+			if len(line) == 0 {
+				continue
+			}
+			t, v, ok := parseSignalRow(line)
+			if !ok {
+				if lineNo == 1 {
+					continue // header
+				}
+				return nil, fmt.Errorf("%s:%d: bad row %q", path, lineNo, line)
+			}
+			rows = append(rows, row{t, v})
+		}
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("no signal rows in %s", src)
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].t < rows[j].t })
+	s := &SignalSeries{
+		Name: filepath.Base(filepath.Clean(dir)),
+		Ts:   make([]int64, 0, len(rows)),
+		Vals: make([]float64, 0, len(rows)),
+	}
+	for _, r := range rows {
+		if n := len(s.Ts); n > 0 && s.Ts[n-1] == r.t {
+			s.Vals[n-1] = r.v
+			continue
+		}
+		s.Ts = append(s.Ts, r.t)
+		s.Vals = append(s.Vals, r.v)
+	}
+	return s, nil
+}
+
+// readSignalFile returns the contents of path, gunzipped for *.gz.
+func readSignalFile(path string) ([]byte, error) {
+	if !strings.HasSuffix(path, ".gz") {
+		return os.ReadFile(path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	defer zr.Close()
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return data, nil
+}
+
+// parseSignalRow parses "ts_ms,signal". Extra columns are ignored.
+func parseSignalRow(line []byte) (int64, float64, bool) {
+	comma := bytes.IndexByte(line, ',')
+	if comma <= 0 {
+		return 0, 0, false
+	}
+	t, ok := fastAtoi(line[:comma])
+	if !ok {
+		return 0, 0, false
+	}
+	rest := line[comma+1:]
+	if i := bytes.IndexByte(rest, ','); i >= 0 {
+		rest = rest[:i]
+	}
+	v, ok := fastParseFloat(rest)
+	if !ok {
+		return 0, 0, false
+	}
+	return t, v, true
+}
+
+// fastAtoi parses a non-negative base-10 integer.
+func fastAtoi(b []byte) (int64, bool) {
+	b = trimSpaces(b)
+	if len(b) == 0 || len(b) > 18 {
+		return 0, false
+	}
+	var n int64
+	for _, c := range b {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		n = n*10 + int64(c-'0')
+	}
+	return n, true
+}
+
+// fastParseFloat parses a plain, optionally signed decimal ("123", "-0.25")
+// without allocating and falls back to strconv.ParseFloat for anything else
+// (exponents such as "1.5e-4", NaN/Inf spellings). The fast path is exact
+// only while the mantissa and the power of ten are both exact float64s, so
+// a mantissa above 2^53 or more than 22 fraction digits also fall back.
+func fastParseFloat(b []byte) (float64, bool) {
+	b = trimSpaces(b)
+	if len(b) == 0 {
+		return 0, false
+	}
+	full := b
+	negative := false
+	switch b[0] {
+	case '-':
+		negative, b = true, b[1:]
+	case '+':
+		b = b[1:]
+	}
+	var mant uint64
+	digits, frac := 0, -1
+	for i, c := range b {
+		switch {
+		case c >= '0' && c <= '9':
+			if digits >= 18 {
+				return slowParseFloat(full)
+			}
+			mant = mant*10 + uint64(c-'0')
+			digits++
+			if frac >= 0 {
+				frac++
+			}
+		case c == '.' && frac < 0 && i < len(b)-1:
+			frac = 0
+		default:
+			return slowParseFloat(full)
+		}
+	}
+	if digits == 0 {
+		return 0, false
+	}
+	if mant > 1<<53 || frac > 22 {
+		return slowParseFloat(full)
+	}
+	v := float64(mant)
+	if frac > 0 {
+		v /= math.Pow10(frac)
+	}
+	if negative {
+		v = -v
+	}
+	return v, true
+}
+
+func slowParseFloat(b []byte) (float64, bool) {
+	v, err := strconv.ParseFloat(string(b), 64)
+	return v, err == nil
+}
+
+func trimSpaces(b []byte) []byte {
+	for len(b) > 0 && (b[0] == ' ' || b[0] == '\t') {
+		b = b[1:]
+	}
+	for len(b) > 0 && (b[len(b)-1] == ' ' || b[len(b)-1] == '\t') {
+		b = b[:len(b)-1]
+	}
+	return b
+}
+
+// ---------------------- External signal as a model ----------------------
+
+// timeAware models receive the trade timestamp before each Update.
+type timeAware interface {
+	SetTime(tMs int64)
+}
+
+// SignalDayAlign summarizes how one UTC day of trades lined up with the
+// external signal.
+type SignalDayAlign struct {
+	Rows       int // trades processed
+	Missing    int // trades with no usable signal value (emitted as 0)
+	Signals    int // signal updates stamped on this day
+	Misaligned int // of those, updates outside the day's first..last trade
+}
+
+// SignalAlignStats collects per-day alignment across workers.
+type SignalAlignStats struct {
+	mu   sync.Mutex
+	Days map[int64]SignalDayAlign // key: days since epoch
+}
+
+func NewSignalAlignStats() *SignalAlignStats {
+	return &SignalAlignStats{Days: make(map[int64]SignalDayAlign)}
+}
+
+// ExternalSignal replays a SignalSeries as a ContinuousModel, aligned to
+// trades by timestamp. Within a UTC day, "ffill" uses the latest update at or
+// before the trade; "nearest" uses the closest update either side. Days are
+// never bridged, so trades before a day's first update count as missing
+// under ffill.
+type ExternalSignal struct {
+	series *SignalSeries
+	align  string
+	stats  *SignalAlignStats
+
+	t   int64
+	pos int // first index with Ts > t; -1 = search on next Update
+
+	day            int64
+	dayStart       int // first series index of the current day
+	dayEnd         int // one past the last series index of the current day
+	firstT, lastT  int64
+	rows, missing  int
+	dayInitialized bool
+}
+
+func NewExternalSignal(series *SignalSeries, align string, stats *SignalAlignStats) *ExternalSignal {
+	return &ExternalSignal{series: series, align: align, stats: stats, pos: -1}
+}
+
+func (e *ExternalSignal) Name() string { return "Ext_" + e.series.Name }
+
+// Reset flushes the alignment stats of the day just streamed.
+func (e *ExternalSignal) Reset() {
+	e.flush()
+	e.pos = -1
+}
+
+func (e *ExternalSignal) SetTime(tMs int64) { e.t = tMs }
+
+func (e *ExternalSignal) Update(dt float64, p, v float64) float64 {
+	ts := e.series.Ts
+	day := e.t / dayMillis
+	if !e.dayInitialized || day != e.day {
+		e.flush()
+		e.day = day
+		e.dayStart = sort.Search(len(ts), func(i int) bool { return ts[i] >= day*dayMillis })
+		e.dayEnd = sort.Search(len(ts), func(i int) bool { return ts[i] >= (day+1)*dayMillis })
+		e.firstT = e.t
+		e.dayInitialized = true
+		e.pos = -1
+	}
+	e.rows++
+	e.lastT = e.t
+
+	if e.pos < 0 {
+		e.pos = sort.Search(len(ts), func(i int) bool { return ts[i] > e.t })
+	}
+	for e.pos < len(ts) && ts[e.pos] <= e.t {
+		e.pos++
+	}
+
+	prev := e.pos - 1 // latest update at or before t
+	if e.align == "nearest" {
+		next := e.pos
+		hasPrev := prev >= e.dayStart
+		hasNext := next < e.dayEnd
+		switch {
+		case hasPrev && (!hasNext || e.t-ts[prev] <= ts[next]-e.t):
+			return e.series.Vals[prev]
+		case hasNext:
+			return e.series.Vals[next]
+		}
+		e.missing++
+		return 0
+	}
+	if prev >= e.dayStart {
+		return e.series.Vals[prev]
+	}
+	e.missing++
+	return 0
+}
+
+// flush records the current day into the shared stats.
+func (e *ExternalSignal) flush() {
+	if !e.dayInitialized || e.rows == 0 {
+		return
+	}
+	ts := e.series.Ts
+	d := SignalDayAlign{Rows: e.rows, Missing: e.missing, Signals: e.dayEnd - e.dayStart}
+	for i := e.dayStart; i < e.dayEnd; i++ {
+		if ts[i] < e.firstT || ts[i] > e.lastT {
+			d.Misaligned++
+		}
+	}
+
+	e.stats.mu.Lock()
+	agg := e.stats.Days[e.day]
+	agg.Rows += d.Rows
+	agg.Missing += d.Missing
+	// The same day is only streamed once, so signal counts are not summed.
+	agg.Signals = d.Signals
+	agg.Misaligned = d.Misaligned
+	e.stats.Days[e.day] = agg
+	e.stats.mu.Unlock()
+
+	e.rows, e.missing = 0, 0
+	e.dayInitialized = false
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestFastParseFloatMatchesStrconv(t *testing.T) {
+	for _, in := range []string{
+		"0", "-0", "1", "+2", "-7", "123.456", "-0.25", "+0.125", ".5", "-.5", "1.",
+		"42.000000", "0.000001", "-98765.4321", "123456789012345",
+		"21.426387258237494", "9007199254740993", "0.12345678901234567", "-1234567.891234567",
+		"-1.5e-3", "1E6", "2.5e+10", "-3E-2", "1e400", "1234567890123456789",
+		"NaN", "inf", "-Inf",
+		" 3.25", "\t-4\t",
+		"", "-", "+", ".", "-.", "abc", "1.2.3", "1e", "--1", "1-", "0x10", "1_000", "1,5", "e5",
+	} {
+		got, gotOK := fastParseFloat([]byte(in))
+		want, err := strconv.ParseFloat(strings.Trim(in, " \t"), 64)
+		wantOK := err == nil
+		if gotOK != wantOK {
+			t.Errorf("%q: ok = %v, strconv ok = %v (%v)", in, gotOK, wantOK, err)
+			continue
+		}
+		if !gotOK {
+			continue
+		}
+		if got != want && !(math.IsNaN(got) && math.IsNaN(want)) {
+			t.Errorf("%q: got %v, strconv %v", in, got, want)
+		}
+		if math.Signbit(got) != math.Signbit(want) {
+			t.Errorf("%q: sign of %v differs from strconv %v", in, got, want)
+		}
+	}
+}
+
+func TestFastParseFloatRoundTrip(t *testing.T) {
+	// Shortest round-trip strings of random doubles carry 16-17 significant
+	// digits, the range where a rounded mantissa would drift by an ulp.
+	rng := rand.New(rand.NewSource(9))
+	for i := 0; i < 100000; i++ {
+		x := math.Float64frombits(rng.Uint64())
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			continue
+		}
+		if i%2 == 0 {
+			x = math.Ldexp(rng.Float64(), rng.Intn(40)-20) // price/qty-like magnitudes
+		}
+		for _, in := range []string{strconv.FormatFloat(x, 'f', -1, 64), strconv.FormatFloat(x, 'g', -1, 64)} {
+			got, ok := fastParseFloat([]byte(in))
+			want, _ := strconv.ParseFloat(in, 64)
+			if !ok || got != want {
+				t.Fatalf("%q: got %v (ok %v), strconv %v", in, got, ok, want)
+			}
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"math"
 	"sort"
 )
@@ -10,6 +11,14 @@ type StreamResult struct {
 	Prices      []float64 // [sample]
 	Features    []float64 // [sample * numModels]
 	Targets     []float64 // [sample * numHorizons]
+	MinRets     []float64 // [sample * numHorizons] log(min price / entry) up to exit; only with TrackMAE
+	MaxRets     []float64 // [sample * numHorizons] log(max price / entry) up to exit; only with TrackMAE
+	MakerBuy    []float64 // [sample * numHorizons] log(exit / limit) of a filled maker buy, NaN if unfilled; only with MakerSim
+	MakerSell   []float64 // [sample * numHorizons] same for a maker sell (not sign-adjusted)
+	ImpactBps   []float64 // [sample] one-way sqrt impact cost; only with ImpactCoefBps > 0
+	NaNCount    []int     // [model] sampled NaN features, replaced by 0
+	InfCount    []int     // [model] sampled ±Inf features, replaced by 0
+	ClampCount  []int     // [model] z-score floor/cap activations over the day (see zGuard)
 	NumModels   int
 	NumHorizons int
 }
@@ -21,7 +30,7 @@ func RunStream(cols *DayColumns, models []ContinuousModel) StreamResult {
 	}
 
 	numModels := len(models)
-	numHorizons := len(HorizonDelays)
+	numHorizons := len(Horizons)
 
 	for _, m := range models {
 		m.Reset()
@@ -38,13 +47,28 @@ func RunStream(cols *DayColumns, models []ContinuousModel) StreamResult {
 		Prices:      make([]float64, 0, estSamples),
 		Features:    make([]float64, 0, estSamples*numModels),
 		Targets:     nil, // filled after labeling
+		NaNCount:    make([]int, numModels),
+		InfCount:    make([]int, numModels),
+		ClampCount:  make([]int, numModels),
 		NumModels:   numModels,
 		NumHorizons: numHorizons,
 	}
 
+	// Models that align on trade timestamps (external signals).
+	var timed []timeAware
+	for _, m := range models {
+		if ta, ok := m.(timeAware); ok {
+			timed = append(timed, ta)
+		}
+	}
+	sided := sidedModels(models)
+
 	// Scratch slice reused per tick to hold model outputs.
 	currFeats := make([]float64, numModels)
 
+	// Tick index of each sample: the entry point for every horizon scan.
+	sampleIdx := make([]int, 0, estSamples)
+
 	lastT := cols.Times[0]
 	nextSampleT := lastT + (SamplingRateSec * 1000)
 
@@ -59,15 +83,34 @@ func RunStream(cols *DayColumns, models []ContinuousModel) StreamResult {
 		}
 		lastT = t
 
+		for _, ta := range timed {
+			ta.SetTime(t)
+		}
+		for _, s := range sided {
+			s.SetSide(cols.Signs[i])
+		}
 		for j, m := range models {
 			currFeats[j] = m.Update(dt, p, v)
 		}
 
 		if t >= nextSampleT {
+			// Non-finite outputs would poison every downstream moment; zero
+			// them and count per model so the report can flag the model.
+			for j, f := range currFeats {
+				if math.IsNaN(f) {
+					res.NaNCount[j]++
+					currFeats[j] = 0
+				} else if math.IsInf(f, 0) {
+					res.InfCount[j]++
+					currFeats[j] = 0
+				}
+			}
+
 			// Append one sample row.
 			res.Times = append(res.Times, t)
 			res.Prices = append(res.Prices, p)
 			res.Features = append(res.Features, currFeats...)
+			sampleIdx = append(sampleIdx, i)
 
 			for t >= nextSampleT {
 				nextSampleT += (SamplingRateSec * 1000)
@@ -75,54 +118,105 @@ func RunStream(cols *DayColumns, models []ContinuousModel) StreamResult {
 		}
 	}
 
+	for j, m := range models {
+		if c, ok := m.(clampCounter); ok {
+			res.ClampCount[j] = c.Clamps()
+		}
+	}
+
 	sampleCount := len(res.Times)
 	if sampleCount == 0 {
 		return StreamResult{}
 	}
 
 	// Lookahead labeling on the flat arrays.
-	maxTime := cols.Times[n-1]
+	if hasDollarHorizon(Horizons) || ImpactCoefBps > 0 {
+		cols.FillCumNotional()
+	}
 	res.Targets = make([]float64, sampleCount*numHorizons)
+	if TrackMAE {
+		res.MinRets = make([]float64, sampleCount*numHorizons)
+		res.MaxRets = make([]float64, sampleCount*numHorizons)
+	}
+	if ImpactCoefBps > 0 {
+		res.ImpactBps = make([]float64, sampleCount)
+	}
+	if MakerSim {
+		res.MakerBuy = make([]float64, sampleCount*numHorizons)
+		res.MakerSell = make([]float64, sampleCount*numHorizons)
+	}
 
 	validCount := 0
-	ticksTimes := cols.Times
 	ticksPrices := cols.Prices
 
 	for i := 0; i < sampleCount; i++ {
 		basePrice := res.Prices[i]
 		sampleT := res.Times[i]
+		entry := sampleIdx[i]
 
 		valid := true
 		baseTarg := validCount * numHorizons
 
-		for hIdx, delay := range HorizonDelays {
-			targetT := sampleT + delay
-			if targetT > maxTime {
-				valid = false
-				break
-			}
+		// Maker fills do not depend on the horizon: find them once per sample.
+		buyFill, sellFill := -1, -1
+		if MakerSim {
+			buyFill, sellFill = makerFills(cols, entry, int64(MakerFillSec)*1000)
+		}
+
+		// Running path extremes, extended incrementally while exits move forward.
+		scanIdx := entry
+		minP, maxP := basePrice, basePrice
 
-			// Binary search for first tick with time >= targetT.
-			idx := sort.Search(n, func(k int) bool {
-				return ticksTimes[k] >= targetT
-			})
-			if idx == n {
+		for hIdx, h := range Horizons {
+			idx := h.ExitIndex(cols, entry)
+			if idx < 0 {
 				valid = false
 				break
 			}
-			foundP := ticksPrices[idx]
-			if foundP <= 0 {
+			r, ok := forwardReturn(cols, entry, idx)
+			if !ok {
 				valid = false
 				break
 			}
 
-			res.Targets[baseTarg+hIdx] = math.Log(foundP / basePrice)
+			res.Targets[baseTarg+hIdx] = r
+
+			if MakerSim {
+				res.MakerBuy[baseTarg+hIdx] = makerReturn(cols, h, buyFill, basePrice)
+				res.MakerSell[baseTarg+hIdx] = makerReturn(cols, h, sellFill, basePrice)
+			}
+
+			if TrackMAE {
+				if idx+1 < scanIdx {
+					// Shorter exit than the previous horizon (mixed units): rescan.
+					scanIdx = entry
+					minP, maxP = basePrice, basePrice
+				}
+				for ; scanIdx <= idx; scanIdx++ {
+					px := ticksPrices[scanIdx]
+					if px <= 0 {
+						continue
+					}
+					if px < minP {
+						minP = px
+					}
+					if px > maxP {
+						maxP = px
+					}
+				}
+				res.MinRets[baseTarg+hIdx] = math.Log(minP / basePrice)
+				res.MaxRets[baseTarg+hIdx] = math.Log(maxP / basePrice)
+			}
 		}
 
 		if !valid {
 			continue
 		}
 
+		if ImpactCoefBps > 0 {
+			res.ImpactBps[validCount] = impactCostBps(cols, entry)
+		}
+
 		// Pack valid rows to the front (Times, Prices, Features).
 		if validCount != i {
 			res.Times[validCount] = sampleT
@@ -144,6 +238,128 @@ func RunStream(cols *DayColumns, models []ContinuousModel) StreamResult {
 	res.Prices = res.Prices[:validCount]
 	res.Features = res.Features[:validCount*numModels]
 	res.Targets = res.Targets[:validCount*numHorizons]
+	if TrackMAE {
+		res.MinRets = res.MinRets[:validCount*numHorizons]
+		res.MaxRets = res.MaxRets[:validCount*numHorizons]
+	}
+	if ImpactCoefBps > 0 {
+		res.ImpactBps = res.ImpactBps[:validCount]
+	}
+	if MakerSim {
+		res.MakerBuy = res.MakerBuy[:validCount*numHorizons]
+		res.MakerSell = res.MakerSell[:validCount*numHorizons]
+	}
 
 	return res
 }
+
+// impactCostBps is the square-root impact of one ImpactNotional order at
+// trade entry: ImpactCoefBps * sqrt(notional / dollar volume traded over the
+// preceding ImpactWindowSec). Needs cols.CumNotional.
+func impactCostBps(cols *DayColumns, entry int) float64 {
+	cum := cols.CumNotional
+	from := cols.Times[entry] - int64(ImpactWindowSec)*1000
+	first := sort.Search(entry+1, func(k int) bool { return cols.Times[k] > from })
+	vol := cum[entry]
+	if first > 0 {
+		vol -= cum[first-1]
+	}
+	if vol <= 0 {
+		vol = cols.Prices[entry] * cols.Qtys[entry]
+	}
+	if vol <= 0 {
+		return 0
+	}
+	return ImpactCoefBps * math.Sqrt(ImpactNotional/vol)
+}
+
+// makerFills returns the first trades after entry, within windowMs, that
+// would fill a resting limit order at the entry price. Only an aggressor on
+// the other side can hit a resting order: a bid fills on a seller-initiated
+// print at or below it, an ask on a buyer-initiated print at or above it.
+// -1 means no fill.
+func makerFills(cols *DayColumns, entry int, windowMs int64) (buyFill, sellFill int) {
+	buyFill, sellFill = -1, -1
+	limit := cols.Prices[entry]
+	deadline := cols.Times[entry] + windowMs
+	for i := entry + 1; i < cols.Count && cols.Times[i] <= deadline; i++ {
+		p, sign := cols.Prices[i], cols.Signs[i]
+		if buyFill < 0 && sign < 0 && p <= limit {
+			buyFill = i
+		}
+		if sellFill < 0 && sign > 0 && p >= limit {
+			sellFill = i
+		}
+		if buyFill >= 0 && sellFill >= 0 {
+			break
+		}
+	}
+	return buyFill, sellFill
+}
+
+// makerReturn is the log return from the limit price to the horizon exit
+// measured from the fill. NaN when unfilled or the exit falls past the day.
+func makerReturn(cols *DayColumns, h Horizon, fill int, limit float64) float64 {
+	if fill < 0 {
+		return math.NaN()
+	}
+	exit := h.ExitIndex(cols, fill)
+	if exit < 0 || cols.Prices[exit] <= 0 {
+		return math.NaN()
+	}
+	return math.Log(cols.Prices[exit] / limit)
+}
+
+// returnDefLabel describes the active return definition for report headers.
+func returnDefLabel() string {
+	if ReturnDef == "vwap" {
+		return fmt.Sprintf("vwap(%d trades)", VWAPTrades)
+	}
+	return ReturnDef
+}
+
+// forwardReturn computes the target return from entry to exit according to
+// ReturnDef. ok is false when a required price is non-positive.
+func forwardReturn(cols *DayColumns, entry, exit int) (float64, bool) {
+	switch ReturnDef {
+	case "simple":
+		p0, p1 := cols.Prices[entry], cols.Prices[exit]
+		if p0 <= 0 || p1 <= 0 {
+			return 0, false
+		}
+		return p1/p0 - 1, true
+	case "vwap":
+		// The entry window starts at entry (the fill is worked after the
+		// signal); the exit window ends at exit, so the label never reads
+		// trades past the horizon.
+		k := max(VWAPTrades, 1)
+		p0 := windowVWAP(cols, entry, entry+k, entry)
+		p1 := windowVWAP(cols, exit-k+1, exit+1, exit)
+		if p0 <= 0 || p1 <= 0 {
+			return 0, false
+		}
+		return math.Log(p1 / p0), true
+	default:
+		p0, p1 := cols.Prices[entry], cols.Prices[exit]
+		if p0 <= 0 || p1 <= 0 {
+			return 0, false
+		}
+		return math.Log(p1 / p0), true
+	}
+}
+
+// windowVWAP returns the volume-weighted price of trades [from, to), clipped
+// to the day, falling back to the price at anchor if the window has no
+// volume.
+func windowVWAP(cols *DayColumns, from, to, anchor int) float64 {
+	from, to = max(from, 0), min(to, cols.Count)
+	var pv, vol float64
+	for i := from; i < to; i++ {
+		pv += cols.Prices[i] * cols.Qtys[i]
+		vol += cols.Qtys[i]
+	}
+	if vol <= 0 {
+		return cols.Prices[anchor]
+	}
+	return pv / vol
+}
@@ -0,0 +1,23 @@
+package main
+
+import "testing"
+
+func TestMakerFillsNeedOppositeAggressor(t *testing.T) {
+	// Entry at 100, then: a buy through the bid, a sell through the ask,
+	// and finally a sell at the bid and a buy at the ask.
+	cols := &DayColumns{
+		Count:  5,
+		Times:  []int64{0, 1000, 2000, 3000, 4000},
+		Prices: []float64{100, 99.9, 100.1, 100, 100},
+		Signs:  []int8{1, 1, -1, -1, 1},
+	}
+	buy, sell := makerFills(cols, 0, 10_000)
+	if buy != 3 || sell != 4 {
+		t.Fatalf("fills = (bid %d, ask %d), want (3, 4)", buy, sell)
+	}
+
+	// The same-side prints alone never fill, however far through the limit.
+	if buy, sell := makerFills(cols, 0, 2500); buy != -1 || sell != -1 {
+		t.Fatalf("fills within 2.5s = (bid %d, ask %d), want none", buy, sell)
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// symbolSummary is the machine-readable twin of the report, written as
+// Continuous_Algo_Summary_<SYMBOL>.json for jq / pandas comparisons.
+// Non-finite stats are written as null.
+type symbolSummary struct {
+	Symbol       string           `json:"symbol"`
+	GeneratedAt  string           `json:"generatedAt"`
+	Returns      string           `json:"returns"`
+	TrainFrac    float64          `json:"trainFrac"`
+	InSampleOnly bool             `json:"inSampleOnly"`
+	TestStart    string           `json:"testStart"` // first sample of the test segment (UTC)
+	Horizons     []horizonSummary `json:"horizons"`
+	Ranking      []rankRow        `json:"ranking"` // full top table, best score first
+}
+
+type horizonSummary struct {
+	Horizon string         `json:"horizon"`
+	Models  []modelSummary `json:"models"`
+}
+
+type modelSummary struct {
+	Name string      `json:"name"`
+	IS   ReportStats `json:"is"` // same suite on the train segment, no bootstrap bands
+	OOS  ReportStats `json:"oos"`
+}
+
+// writeJSONSummary writes the core per (model, horizon) stats of one symbol.
+func writeJSONSummary(path, sym string, modelNames []string, allStats [][]ReportStats, results [][]*ResultContainer, ranking []rankRow, trainFrac float64) error {
+	sum := symbolSummary{
+		Symbol:       sym,
+		GeneratedAt:  time.Now().UTC().Format(time.RFC3339),
+		Returns:      returnDefLabel(),
+		TrainFrac:    trainFrac,
+		InSampleOnly: NoOOS,
+	}
+	for _, row := range results {
+		for _, rc := range row {
+			if n := len(rc.Times); n > 0 && sum.TestStart == "" {
+				sum.TestStart = time.UnixMilli(int64(rc.Times[trainCount(n, trainFrac)])).UTC().Format(time.RFC3339)
+			}
+		}
+	}
+
+	for hIdx, hName := range HorizonLabels {
+		hs := horizonSummary{Horizon: hName}
+		for mIdx, name := range modelNames {
+			stats := allStats[mIdx][hIdx]
+			if stats.TestCount == 0 {
+				continue
+			}
+			data := results[hIdx][mIdx]
+			is := AnalyzeFullSuiteIS(data.Times, data.Feats, data.Targs, trainFrac)
+			hs.Models = append(hs.Models, modelSummary{Name: name, IS: is, OOS: stats})
+		}
+		sum.Horizons = append(sum.Horizons, hs)
+	}
+	sum.Ranking = ranking
+
+	raw, err := json.MarshalIndent(finiteJSON(reflect.ValueOf(sum)), "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, raw)
+}
+
+// finiteJSON mirrors v as fresh JSON values with every NaN/Inf float
+// (including inside slices) turned into null; encoding/json refuses to
+// encode them. Struct fields keep their order and json tag names, and v
+// itself is left untouched.
+func finiteJSON(v reflect.Value) any {
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		if f := v.Float(); !math.IsNaN(f) && !math.IsInf(f, 0) {
+			return f
+		}
+		return nil
+	case reflect.Struct:
+		t := v.Type()
+		obj := make(jsonObject, 0, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			obj = append(obj, jsonField{name, finiteJSON(v.Field(i))})
+		}
+		return obj
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		out := make([]any, v.Len())
+		for i := range out {
+			out[i] = finiteJSON(v.Index(i))
+		}
+		return out
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return finiteJSON(v.Elem())
+	}
+	return v.Interface()
+}
+
+type jsonField struct {
+	Name  string
+	Value any
+}
+
+// jsonObject is a JSON object whose keys keep their insertion order.
+type jsonObject []jsonField
+
+func (o jsonObject) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, f := range o {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(f.Name)
+		val, err := json.Marshal(f.Value)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(val)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
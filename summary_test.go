@@ -0,0 +1,33 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFiniteJSONNullsWithoutMutating(t *testing.T) {
+	st := ReportStats{PearsonIC: math.NaN(), Sharpe: 1.5, DecileMean: []float64{0.1, math.Inf(1)}}
+	row := rankRow{Model: "m", OOSIC: math.Inf(-1)}
+	raw, err := json.Marshal(finiteJSON(reflect.ValueOf(struct {
+		Stats ReportStats `json:"stats"`
+		Rank  []rankRow   `json:"rank"`
+	}{st, []rankRow{row}})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(raw)
+	for _, want := range []string{`"PearsonIC":null`, `"Sharpe":1.5`, `"DecileMean":[0.1,null]`, `"oosIC":null`, `"model":"m"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %s in %s", want, out)
+		}
+	}
+	if strings.Index(out, `"TrainCount"`) > strings.Index(out, `"PearsonIC"`) {
+		t.Errorf("fields out of struct order: %s", out)
+	}
+	if !math.IsNaN(st.PearsonIC) || !math.IsInf(st.DecileMean[1], 1) || !math.IsInf(row.OOSIC, -1) {
+		t.Error("finiteJSON modified its input")
+	}
+}
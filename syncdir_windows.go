@@ -0,0 +1,7 @@
+//go:build windows
+
+package main
+
+// syncDir is a no-op: Windows cannot flush a directory handle, and NTFS
+// journals the rename itself.
+func syncDir(dir string) error { return nil }
@@ -0,0 +1,48 @@
+package main
+
+// dashboardTemplate renders a symbol's OOS results as one self-contained
+// HTML page (-html): summary table, daily IC sparklines and IS vs OOS.
+const dashboardTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Symbol}} OOS dashboard</title>
+<style>
+body { background: #111418; color: #d8dee6; font: 13px/1.4 Menlo, Consolas, monospace; margin: 24px; }
+h1, h2 { color: #f0f4f8; font-weight: normal; }
+h2 { margin-top: 32px; border-bottom: 1px solid #2a3038; padding-bottom: 4px; }
+table { border-collapse: collapse; }
+th, td { padding: 3px 10px; text-align: right; }
+th { color: #8fa1b3; border-bottom: 1px solid #2a3038; }
+td:first-child, th:first-child, td:nth-child(2), th:nth-child(2) { text-align: left; }
+tr:nth-child(even) td { background: #171b20; }
+.pos { color: #7fd18b; }
+.neg { color: #e6786f; }
+.meta { color: #8fa1b3; }
+pre { margin: 0; font-size: 15px; letter-spacing: -1px; }
+</style>
+</head>
+<body>
+<h1>{{.Symbol}} — continuous-time models, OOS</h1>
+<p class="meta">Generated {{.Generated}} | Returns: {{.Returns}} | Horizons: {{.Horizons}} | Train fraction: {{printf "%.2f" .TrainFrac}}{{if .InSampleOnly}} | IN-SAMPLE ONLY (-no-oos){{end}}</p>
+
+<h2>Summary (test segment)</h2>
+<table>
+<tr><th>Model</th><th>Horizon</th><th>TestN</th><th>PearsonIC</th><th>SpearmanIC</th><th>IC_T(eff)</th><th>HitRate</th><th>AnnSharpe</th><th>PSR</th><th>BE(bps)</th></tr>
+{{range .Rows}}<tr><td>{{.Model}}</td><td>{{.Horizon}}</td><td>{{.TestN}}</td><td class="{{sign .PearsonIC}}">{{printf "%.4f" .PearsonIC}}</td><td class="{{sign .SpearmanIC}}">{{printf "%.4f" .SpearmanIC}}</td><td>{{printf "%.2f" .ICT}}</td><td>{{printf "%.3f" .HitRate}}</td><td class="{{sign .AnnSharpe}}">{{printf "%.2f" .AnnSharpe}}</td><td>{{printf "%.3f" .PSR}}</td><td class="{{sign .BreakevenBps}}">{{printf "%+.2f" .BreakevenBps}}</td></tr>
+{{end}}</table>
+
+<h2>Daily IC (test segment)</h2>
+<table>
+<tr><th>Model</th><th>Horizon</th><th>Days</th><th>Positive</th><th>Min</th><th>Max</th><th style="text-align:left">Daily IC</th></tr>
+{{range .Daily}}<tr><td>{{.Model}}</td><td>{{.Horizon}}</td><td>{{.Days}}</td><td>{{printf "%.0f%%" .PosPct}}</td><td>{{printf "%.3f" .Min}}</td><td>{{printf "%.3f" .Max}}</td><td style="text-align:left"><pre>{{.Spark}}</pre></td></tr>
+{{end}}</table>
+
+<h2>IS vs OOS</h2>
+<table>
+<tr><th>Model</th><th>Horizon</th><th>IS_N</th><th>IS_IC</th><th>OOS_N</th><th>OOS_IC</th><th>Decay</th></tr>
+{{range .Split}}<tr><td>{{.Model}}</td><td>{{.Horizon}}</td><td>{{.ISN}}</td><td class="{{sign .ISIC}}">{{printf "%.4f" .ISIC}}</td><td>{{.OOSN}}</td><td class="{{sign .OOSIC}}">{{printf "%.4f" .OOSIC}}</td><td>{{printf "%+.4f" .Decay}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`
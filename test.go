@@ -2,8 +2,12 @@ package main
 
 import (
 	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
 	"os"
 	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"text/tabwriter"
@@ -11,16 +15,137 @@ import (
 )
 
 type ResultContainer struct {
+	Tag uint64 // containerTag(model, horizon); 0 = untagged
+
 	Times []float64
 	Feats []float64
 	Targs []float64
+	MinRs []float64 // path minimum log return (TrackMAE only)
+	MaxRs []float64 // path maximum log return (TrackMAE only)
+	MkBuy []float64 // maker buy return from fill, NaN if unfilled (MakerSim only)
+	MkSel []float64 // maker sell return from fill, NaN if unfilled (MakerSim only)
+	Imp   []float64 // one-way sqrt impact cost in bps (ImpactCoefBps > 0 only)
+}
+
+// ResultTagMismatch counts merges refused because the containers belonged
+// to different (model, horizon) cells. Any non-zero value is a bug.
+var ResultTagMismatch atomic.Int64
+
+// containerTag identifies the (model, horizon) cell a container belongs to.
+func containerTag(model, horizon string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(model))
+	h.Write([]byte{0})
+	h.Write([]byte(horizon))
+	return h.Sum64()
+}
+
+// Add appends src's samples to rc. Containers tagged for different cells
+// are never merged; the mismatch is logged and counted instead.
+func (rc *ResultContainer) Add(src *ResultContainer) {
+	if len(src.Times) == 0 {
+		return
+	}
+	if rc.Tag != 0 && src.Tag != 0 && rc.Tag != src.Tag {
+		ResultTagMismatch.Add(1)
+		fmt.Printf("WARN: refusing to merge result containers with different tags (%016x into %016x)\n", src.Tag, rc.Tag)
+		return
+	}
+
+	rc.Times = append(rc.Times, src.Times...)
+	rc.Feats = append(rc.Feats, src.Feats...)
+	rc.Targs = append(rc.Targs, src.Targs...)
+	rc.MinRs = append(rc.MinRs, src.MinRs...)
+	rc.MaxRs = append(rc.MaxRs, src.MaxRs...)
+	rc.MkBuy = append(rc.MkBuy, src.MkBuy...)
+	rc.MkSel = append(rc.MkSel, src.MkSel...)
+	rc.Imp = append(rc.Imp, src.Imp...)
+}
+
+// SortByTime orders all columns chronologically. Sample times are unique per
+// container, so later in-place sorts by time (splitTrainTest) leave the
+// order unchanged and the optional columns stay aligned.
+func (rc *ResultContainer) SortByTime() {
+	n := len(rc.Times)
+	perm := make([]int, n)
+	for i := range perm {
+		perm[i] = i
+	}
+	sort.Slice(perm, func(i, j int) bool { return rc.Times[perm[i]] < rc.Times[perm[j]] })
+
+	apply := func(col []float64) {
+		if len(col) != n {
+			return
+		}
+		tmp := make([]float64, n)
+		for i, p := range perm {
+			tmp[i] = col[p]
+		}
+		copy(col, tmp)
+	}
+	apply(rc.Times)
+	apply(rc.Feats)
+	apply(rc.Targs)
+	apply(rc.MinRs)
+	apply(rc.MaxRs)
+	apply(rc.MkBuy)
+	apply(rc.MkSel)
+	apply(rc.Imp)
+}
+
+// Truncate keeps the first n samples (call after SortByTime).
+func (rc *ResultContainer) Truncate(n int) {
+	cut := func(col []float64) []float64 {
+		if len(col) > n {
+			return col[:n]
+		}
+		return col
+	}
+	rc.Times = cut(rc.Times)
+	rc.Feats = cut(rc.Feats)
+	rc.Targs = cut(rc.Targs)
+	rc.MinRs = cut(rc.MinRs)
+	rc.MaxRs = cut(rc.MaxRs)
+	rc.MkBuy = cut(rc.MkBuy)
+	rc.MkSel = cut(rc.MkSel)
+	rc.Imp = cut(rc.Imp)
 }
 
 // Per-worker storage: [horizon][model] -> ResultContainer
 type WorkerResults struct {
 	Data [][]*ResultContainer
+
+	// Non-finite feature counts per model (see StreamResult).
+	NaN []int64
+	Inf []int64
+
+	// z-score clamp activations per model and days with any (see zGuard).
+	Clamps    []int64
+	ClampDays []int64
+
+	// Wall time of every day this worker processed.
+	Timings []dayTiming
+
+	// Days that failed to load, and days below MinRowsPerDay.
+	Failed int
+	Short  []skippedDay
 }
 
+// testLog, when non-nil, receives a copy of every report table (-log).
+var testLog io.Writer
+
+// testExport, when non-nil, receives per-day stats rows (-export-csv).
+var testExport *dayStatsExporter
+
+// testTiming, when non-nil, receives per-day timing rows (-timing-csv).
+var testTiming *dayTimingExporter
+
+// testPortfolio collects every symbol's leg for -portfolio (nil when off).
+var testPortfolio *portfolioBook
+
+// testXS collects every symbol's samples for -xs (nil when off).
+var testXS *xsBook
+
 // RunTest now runs the full OOS pipeline for **all discovered symbols** under BaseDir.
 // For each symbol, it calls RunTestForSymbol and writes a separate report file:
 //
@@ -39,8 +164,82 @@ func RunTest() {
 	}
 	sort.Strings(symbols)
 
+	if LogPath != "" && !DryRun {
+		lf, err := os.Create(LogPath)
+		if err != nil {
+			fmt.Printf("ERROR: could not create log file %s: %v\n", LogPath, err)
+			return
+		}
+		defer lf.Close()
+		fmt.Fprintf(lf, "# %s | %s\n\n", startAll.Format(time.RFC3339), strings.Join(os.Args, " "))
+		testLog = lf
+		defer func() { testLog = nil }()
+	}
+
+	if ExportCSVPath != "" && !DryRun {
+		ex, err := newDayStatsExporter(ExportCSVPath)
+		if err != nil {
+			fmt.Printf("ERROR: could not create export file %s: %v\n", ExportCSVPath, err)
+			return
+		}
+		testExport = ex
+		defer func() {
+			if err := ex.Close(); err != nil {
+				fmt.Printf("ERROR: writing %s: %v\n", ExportCSVPath, err)
+			}
+			testExport = nil
+		}()
+	}
+
+	if TimingCSVPath != "" && !DryRun {
+		ex, err := newDayTimingExporter(TimingCSVPath)
+		if err != nil {
+			fmt.Printf("ERROR: could not create timing file %s: %v\n", TimingCSVPath, err)
+			return
+		}
+		testTiming = ex
+		defer func() {
+			if err := ex.Close(); err != nil {
+				fmt.Printf("ERROR: writing %s: %v\n", TimingCSVPath, err)
+			}
+			testTiming = nil
+		}()
+	}
+
+	if PortfolioSpec != "" {
+		book, err := newPortfolioBook(PortfolioSpec)
+		if err != nil {
+			fmt.Printf("ERROR: -portfolio: %v\n", err)
+			return
+		}
+		testPortfolio = book
+		defer func() { testPortfolio = nil }()
+	}
+
+	if CrossSection {
+		testXS = newXSBook()
+		defer func() { testXS = nil }()
+	}
+
 	fmt.Printf(">>> CONTINUOUS-TIME ALGO DISCOVERY (OOS REPORT, ALL SYMBOLS) <<<\n")
 	fmt.Printf("   Workers: %d | Symbols: %d\n\n", CPUThreads, len(symbols))
+	if DryRun {
+		var outs []string
+		for _, p := range []string{LogPath, ExportCSVPath, TimingCSVPath} {
+			if p != "" {
+				outs = append(outs, p)
+			}
+		}
+		if PortfolioSpec != "" {
+			outs = append(outs, PortfolioPath)
+		}
+		if CrossSection {
+			outs = append(outs, CrossSectionPath)
+		}
+		if len(outs) > 0 {
+			fmt.Printf("   Dry run; across all symbols would also write: %s\n\n", strings.Join(outs, ", "))
+		}
+	}
 
 	for _, sym := range symbols {
 		fmt.Printf("=== [%s] Starting OOS discovery ===\n", sym)
@@ -48,14 +247,50 @@ func RunTest() {
 		fmt.Printf("=== [%s] Finished OOS discovery ===\n\n", sym)
 	}
 
-	fmt.Printf("All symbols completed in %s\n", time.Since(startAll))
+	if testPortfolio != nil && !PlanOnly {
+		if err := testPortfolio.WriteReport(PortfolioPath); err != nil {
+			fmt.Printf("ERROR: portfolio report: %v\n", err)
+		} else {
+			fmt.Printf("Portfolio report: %s\n", PortfolioPath)
+		}
+	}
+
+	if testXS != nil && !PlanOnly {
+		if err := testXS.WriteReport(CrossSectionPath, CrossSectionMinSyms); err != nil {
+			fmt.Printf("ERROR: cross-sectional report: %v\n", err)
+		} else {
+			fmt.Printf("Cross-sectional report: %s\n", CrossSectionPath)
+		}
+	}
+
+	if n := ResultTagMismatch.Load(); n > 0 {
+		fmt.Printf("WARN: %d result merges were refused due to (model, horizon) tag mismatch; this is a bug in the aggregation loop.\n", n)
+	}
+	fmt.Printf("All symbols completed in %s | mem: %s\n", time.Since(startAll), getMemUsage())
 }
 
 // RunTestForSymbol runs the original OOS pipeline for a single symbol.
 func RunTestForSymbol(sym string) {
 	start := time.Now()
 
-	models := GetContinuousModels()
+	// Optional external signal, evaluated as one more model.
+	var extSeries *SignalSeries
+	extAlign := NewSignalAlignStats()
+	if SignalDir != "" {
+		var err error
+		if extSeries, err = LoadSignalSeries(SignalDir, sym); err != nil {
+			fmt.Printf("[%s] WARN: external signal not loaded: %v\n", sym, err)
+		}
+	}
+	symbolModels := func() []ContinuousModel {
+		ms := GetContinuousModels()
+		if extSeries != nil {
+			ms = append(ms, NewExternalSignal(extSeries, SignalAlign, extAlign))
+		}
+		return ms
+	}
+
+	models := symbolModels()
 	modelNames := make([]string, len(models))
 	for i, m := range models {
 		modelNames[i] = m.Name()
@@ -69,7 +304,7 @@ func RunTestForSymbol(sym string) {
 	for h := range results {
 		results[h] = make([]*ResultContainer, len(models))
 		for m := range results[h] {
-			results[h][m] = &ResultContainer{}
+			results[h][m] = &ResultContainer{Tag: containerTag(modelNames[m], HorizonLabels[h])}
 		}
 	}
 
@@ -84,8 +319,13 @@ func RunTestForSymbol(sym string) {
 		return
 	}
 
-	// Sort tasks chronologically so workers process days in a sensible order.
+	// Schedule the largest days first so no worker is left grinding one giant
+	// day at the end while the others sit idle. Samples are re-sorted by time
+	// before reporting, so processing order does not affect results.
 	sort.Slice(tasks, func(i, j int) bool {
+		if tasks[i].Size != tasks[j].Size {
+			return tasks[i].Size > tasks[j].Size
+		}
 		if tasks[i].Year != tasks[j].Year {
 			return tasks[i].Year < tasks[j].Year
 		}
@@ -95,22 +335,37 @@ func RunTestForSymbol(sym string) {
 		return tasks[i].Day < tasks[j].Day
 	})
 
+	plan := planStudy(tasks, len(models))
+	fmt.Printf("   %s\n", plan)
+	if DryRun {
+		printStudyDryRun(sym, tasks, modelNames)
+	}
+	if PlanOnly {
+		return
+	}
+
 	// Per-worker result storage.
 	workerResults := make([]*WorkerResults, CPUThreads)
 	for i := 0; i < CPUThreads; i++ {
 		wr := &WorkerResults{
 			Data: make([][]*ResultContainer, len(HorizonLabels)),
+			NaN:  make([]int64, len(models)),
+			Inf:  make([]int64, len(models)),
+
+			Clamps:    make([]int64, len(models)),
+			ClampDays: make([]int64, len(models)),
 		}
 		for h := range wr.Data {
 			wr.Data[h] = make([]*ResultContainer, len(models))
 			for m := range wr.Data[h] {
-				wr.Data[h][m] = &ResultContainer{}
+				wr.Data[h][m] = &ResultContainer{Tag: containerTag(modelNames[m], HorizonLabels[h])}
 			}
 		}
 		workerResults[i] = wr
 	}
 
 	// Task channel and worker pool.
+	streamStart := time.Now()
 	taskCh := make(chan ofiTask, len(tasks))
 	for _, t := range tasks {
 		taskCh <- t
@@ -119,6 +374,26 @@ func RunTestForSymbol(sym string) {
 
 	var wg sync.WaitGroup
 	var processed atomic.Int64
+	var completed atomic.Int64 // every task, including failed loads
+
+	// Progress monitor; stopped right after the worker pool drains.
+	stopProgress := make(chan struct{})
+	progressDone := make(chan struct{})
+	go func() {
+		defer close(progressDone)
+		ticker := time.NewTicker(500 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopProgress:
+				printProgress(sym, completed.Load(), int64(len(tasks)), start)
+				fmt.Println()
+				return
+			case <-ticker.C:
+				printProgress(sym, completed.Load(), int64(len(tasks)), start)
+			}
+		}
+	}()
 
 	for wID := 0; wID < CPUThreads; wID++ {
 		wg.Add(1)
@@ -126,7 +401,13 @@ func RunTestForSymbol(sym string) {
 			defer wg.Done()
 
 			localStore := workerResults[id]
-			localModels := GetContinuousModels()
+			localModels := symbolModels()
+			// Resetting flushes per-day state (external signal alignment).
+			defer func() {
+				for _, m := range localModels {
+					m.Reset()
+				}
+			}()
 
 			cols := DayColumnPool.Get().(*DayColumns)
 			defer DayColumnPool.Put(cols)
@@ -134,14 +415,29 @@ func RunTestForSymbol(sym string) {
 			var buf []byte
 
 			for task := range taskCh {
-				if !LoadGNCFile(BaseDir, sym, task, &buf) {
+				completed.Add(1)
+				loadStart := time.Now()
+				if !LoadGNCFile(SymbolRoot(sym), sym, task, &buf) {
+					localStore.Failed++
 					continue
 				}
 				if _, err := InflateGNC(buf, cols); err != nil {
+					localStore.Failed++
+					continue
+				}
+				if isShortDay(cols.Count) {
+					localStore.Short = append(localStore.Short, skippedDay{taskDate(task), cols.Count})
 					continue
 				}
 
+				streamStart := time.Now()
 				streamRes := RunStream(cols, localModels)
+				localStore.Timings = append(localStore.Timings, dayTiming{
+					Date:   taskDate(task),
+					Rows:   cols.Count,
+					Load:   streamStart.Sub(loadStart),
+					Stream: time.Since(streamStart),
+				})
 				if len(streamRes.Times) == 0 {
 					continue
 				}
@@ -150,6 +446,15 @@ func RunTestForSymbol(sym string) {
 				numModels := streamRes.NumModels
 				numHorizons := streamRes.NumHorizons
 
+				for mIdx := 0; mIdx < numModels; mIdx++ {
+					localStore.NaN[mIdx] += int64(streamRes.NaNCount[mIdx])
+					localStore.Inf[mIdx] += int64(streamRes.InfCount[mIdx])
+					if c := streamRes.ClampCount[mIdx]; c > 0 {
+						localStore.Clamps[mIdx] += int64(c)
+						localStore.ClampDays[mIdx]++
+					}
+				}
+
 				// Append into thread-local storage.
 				for s := 0; s < numSamples; s++ {
 					t := float64(streamRes.Times[s])
@@ -166,6 +471,17 @@ func RunTestForSymbol(sym string) {
 							rc.Times = append(rc.Times, t)
 							rc.Feats = append(rc.Feats, featVal)
 							rc.Targs = append(rc.Targs, targVal)
+							if TrackMAE {
+								rc.MinRs = append(rc.MinRs, streamRes.MinRets[targBase+hIdx])
+								rc.MaxRs = append(rc.MaxRs, streamRes.MaxRets[targBase+hIdx])
+							}
+							if MakerSim {
+								rc.MkBuy = append(rc.MkBuy, streamRes.MakerBuy[targBase+hIdx])
+								rc.MkSel = append(rc.MkSel, streamRes.MakerSell[targBase+hIdx])
+							}
+							if ImpactCoefBps > 0 {
+								rc.Imp = append(rc.Imp, streamRes.ImpactBps[s])
+							}
 						}
 					}
 				}
@@ -175,26 +491,115 @@ func RunTestForSymbol(sym string) {
 		}(wID)
 	}
 	wg.Wait()
+	close(stopProgress)
+	<-progressDone
+
+	// Nothing streamed: say why instead of writing a report of empty tables
+	// (and keep the throughput calibration clean).
+	if processed.Load() == 0 {
+		var failed, short int
+		for _, wr := range workerResults {
+			failed += wr.Failed
+			short += len(wr.Short)
+		}
+		fmt.Printf("[%s] No usable days out of %d: %d failed to load, %d below -min-rows %d, %d without samples. No report written.\n",
+			sym, len(tasks), failed, short, MinRowsPerDay, len(tasks)-failed-short)
+		return
+	}
+
+	streamElapsed := time.Since(streamStart)
 
 	// Merge worker-local results into global results.
+	nanCount := make([]int64, len(models))
+	infCount := make([]int64, len(models))
+	clampCount := make([]int64, len(models))
+	clampDays := make([]int64, len(models))
+	var timings []dayTiming
+	var failedDays int
+	var shortDays []skippedDay
 	for wID := 0; wID < CPUThreads; wID++ {
 		wr := workerResults[wID]
+		for mIdx := range models {
+			nanCount[mIdx] += wr.NaN[mIdx]
+			infCount[mIdx] += wr.Inf[mIdx]
+			clampCount[mIdx] += wr.Clamps[mIdx]
+			clampDays[mIdx] += wr.ClampDays[mIdx]
+		}
+		timings = append(timings, wr.Timings...)
+		failedDays += wr.Failed
+		shortDays = append(shortDays, wr.Short...)
 		for hIdx := range HorizonLabels {
 			for mIdx := range models {
-				src := wr.Data[hIdx][mIdx]
-				dst := results[hIdx][mIdx]
+				results[hIdx][mIdx].Add(wr.Data[hIdx][mIdx])
+			}
+		}
+	}
 
-				if len(src.Times) == 0 {
-					continue
-				}
+	// Calibrate on the days actually streamed: failed and short days cost
+	// next to nothing and would inflate the measured throughput.
+	streamed := make(map[time.Time]bool, len(timings))
+	for _, tm := range timings {
+		streamed[tm.Date] = true
+	}
+	var streamedTasks []ofiTask
+	for _, t := range tasks {
+		if streamed[taskDate(t)] {
+			streamedTasks = append(streamedTasks, t)
+		}
+	}
+	if err := updatePlanCalibration(planStudy(streamedTasks, len(models)).RowModels, CPUThreads, streamElapsed); err != nil {
+		fmt.Printf("[%s] WARN: could not save plan calibration: %v\n", sym, err)
+	}
+
+	trainFrac := TrainFrac // earliest samples train, latest samples test
+
+	for hIdx := range results {
+		for _, rc := range results[hIdx] {
+			rc.SortByTime()
+			if NoOOS {
+				// Withhold the test segment entirely; every "OOS" section
+				// below then evaluates on the tail of the train segment.
+				rc.Truncate(trainCount(len(rc.Times), trainFrac))
+			}
+		}
+	}
+
+	if testExport != nil {
+		for hIdx, hName := range HorizonLabels {
+			for mIdx, name := range modelNames {
+				testExport.WriteContainer(sym, name, hName, results[hIdx][mIdx], trainFrac)
+			}
+		}
+	}
+
+	if testPortfolio != nil {
+		testPortfolio.AddSymbol(sym, modelNames, results, trainFrac)
+	}
+	if testXS != nil {
+		testXS.AddSymbol(sym, modelNames, results, trainFrac)
+	}
 
-				dst.Times = append(dst.Times, src.Times...)
-				dst.Feats = append(dst.Feats, src.Feats...)
-				dst.Targs = append(dst.Targs, src.Targs...)
+	// Ledger: count earlier OOS evaluations before recording this one and
+	// each -oos-dates section, which looks at test data just the same.
+	var priorEvals map[string]int
+	if UseLedger && !NoOOS {
+		priorEvals = ledgerPriorEvals(sym)
+		for _, from := range append([]time.Time{{}}, OOSDates...) {
+			if err := ledgerAppend(sym, modelNames, trainFrac, from); err != nil {
+				fmt.Printf("[%s] WARN: could not append to OOS ledger: %v\n", sym, err)
 			}
 		}
 	}
 
+	for mIdx, name := range modelNames {
+		if nanCount[mIdx] > 0 {
+			fmt.Printf("[%s] WARN: NaN detected in model %s (%d samples zeroed)\n", sym, name, nanCount[mIdx])
+		}
+		if infCount[mIdx] > 0 {
+			fmt.Printf("[%s] WARN: Inf detected in model %s (%d samples zeroed)\n", sym, name, infCount[mIdx])
+		}
+	}
+
 	// ---------------------------------------------------------------------
 	// Reporting phase (per symbol)
 	// ---------------------------------------------------------------------
@@ -207,13 +612,58 @@ func RunTestForSymbol(sym string) {
 		return
 	}
 	defer f.Close()
-	w := tabwriter.NewWriter(f, 0, 0, 1, ' ', 0)
+	var out io.Writer = f
+	if testLog != nil {
+		out = io.MultiWriter(f, testLog)
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 1, ' ', 0)
+
+	fmt.Fprintf(w, "# Symbol: %s | Returns: %s | Horizons: %s | DaysPerYear: %g\n", sym, returnDefLabel(), strings.Join(HorizonLabels, ","), DaysPerYear)
+	fmt.Fprintf(w, "# Non-finite features (zeroed):")
+	for mIdx, name := range modelNames {
+		fmt.Fprintf(w, " %s NaN=%d Inf=%d;", name, nanCount[mIdx], infCount[mIdx])
+	}
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "# Skipped days: %d failed to load, %d short (< %d rows)\n", failedDays, len(shortDays), MinRowsPerDay)
+	fmt.Fprintf(w, "# Z-score clamps (variance floor %g x long-run, |z| cap %g):", ZVarFloor, ZClamp)
+	for mIdx, name := range modelNames {
+		if clampCount[mIdx] > 0 {
+			fmt.Fprintf(w, " %s %d on %d days;", name, clampCount[mIdx], clampDays[mIdx])
+		}
+	}
+	fmt.Fprintf(w, "\n")
+	if NoOOS {
+		fmt.Fprintf(w, "# IS-ONLY (-no-oos): test segment withheld; sections labelled OOS use the last %.0f%% of the train segment\n", 100*(1-trainFrac))
+	}
+	if priorEvals != nil {
+		fmt.Fprintf(w, "# OOS ledger (%s, config %s): prior evaluations of this test segment:", LedgerPath, configHash(trainFrac))
+		for _, name := range modelNames {
+			fmt.Fprintf(w, " %s=%d;", name, priorEvals[name])
+		}
+		fmt.Fprintf(w, "\n")
+		for _, name := range modelNames {
+			if n := priorEvals[name]; n > 0 {
+				fmt.Fprintf(w, "# WARNING: %s has been evaluated OOS %d time(s) before; its OOS metrics are no longer a clean holdout\n", name, n)
+			}
+		}
+	}
+	fmt.Fprintf(w, "\n")
 
-	const trainFrac = 0.7 // 70% earliest samples train, 30% latest samples test
+	// 0) Feature value statistics (-feature-stats), before any labels.
+	if FeatureStats {
+		writeFeatureStats(w, modelNames, results, nanCount, infCount)
+	}
 
 	// 1) Core OOS summary, per model × horizon
-	fmt.Fprintf(w, "MODEL\tHORIZON\tTrainN\tTestN\tPearsonIC\tSpearmanIC\tHitRate\tHitZ\tSharpe\tSpread(bps)\tTopDecile(bps)\tBotDecile(bps)\tMI(bits)\tNMI\tΔLogLoss\n")
-	fmt.Fprintf(w, "-----\t-------\t------\t-----\t---------\t-----------\t-------\t----\t------\t-----------\t--------------\t---------------\t--------\t---\t--------\n")
+	fmt.Fprintf(w, "# IC_T(eff) = pooled Pearson IC t-stat on overlap-adjusted EffN; DayIC_T = mean/SE of daily ICs (stability); PSR = P(true Sharpe > 0) adjusted for PnL skew/kurtosis; MaxDD%%/DayDD%% = worst sign-strategy drawdown over the test segment / within one day; BE/IC _P5/_P95 = day-block bootstrap band (%d iters, %d-day blocks)\n", BootstrapIters, BootstrapBlockDays)
+	fmt.Fprintf(w, "MODEL\tHORIZON\tTrainN\tTestN\tPearsonIC\tSpearmanIC\tEffN\tIC_T(eff)\tDayIC_T\tHitRate\tHitZ\tSharpe\tAnnSharpe\tPSR\tMaxDD%%\tDayDD%%\tSpread(bps)\tTopDecile(bps)\tBotDecile(bps)\tMI(bits)\tNMI\tΔLogLoss\tSkew\tExKurt\tBE(bps)\tBE_P5\tBE_P95\tIC_P5\tIC_P95\n")
+	fmt.Fprintf(w, "-----\t-------\t------\t-----\t---------\t-----------\t----\t---------\t-------\t-------\t----\t------\t---------\t---\t------\t------\t-----------\t--------------\t---------------\t--------\t---\t--------\t----\t------\t-------\t-----\t------\t-----\t------\n")
+
+	// Core stats are kept per [model][horizon] so later sections can reuse them.
+	allStats := make([][]ReportStats, len(models))
+	for mIdx := range allStats {
+		allStats[mIdx] = make([]ReportStats, len(HorizonLabels))
+	}
 
 	for mIdx, name := range modelNames {
 		for hIdx, hName := range HorizonLabels {
@@ -223,33 +673,104 @@ func RunTestForSymbol(sym string) {
 			}
 
 			stats := AnalyzeFullSuiteOOS(data.Times, data.Feats, data.Targs, trainFrac)
+			allStats[mIdx][hIdx] = stats
 			if stats.TestCount == 0 {
 				continue
 			}
 
 			fmt.Fprintf(
 				w,
-				"%s\t%s\t%d\t%d\t%.4f\t%.4f\t%.3f\t%.2f\t%.3f\t%+.1f\t%+.1f\t%+.1f\t%.3f\t%.3f\t%.4f\n",
+				"%s\t%s\t%d\t%d\t%.4f\t%.4f\t%.0f\t%.2f\t%.2f\t%.3f\t%.2f\t%.3f\t%.2f\t%.3f\t%.2f\t%.2f\t%+.1f\t%+.1f\t%+.1f\t%.3f\t%.3f\t%.4f\t%+.2f\t%.2f\t%+.2f\t%+.2f\t%+.2f\t%.4f\t%.4f\n",
 				name,
 				hName,
 				stats.TrainCount,
 				stats.TestCount,
 				stats.PearsonIC,
 				stats.SpearmanIC,
+				stats.EffN,
+				stats.ICTEff,
+				stats.DailyICT,
 				stats.HitRate,
 				stats.HitRateZ,
 				stats.Sharpe,
+				stats.AnnualizedSharpe,
+				stats.PSR,
+				stats.MaxDrawdown*100,
+				stats.MaxDayDrawdown*100,
 				stats.SpreadBps,
 				stats.TopDecileRetBps,
 				stats.BottomDecileRetBps,
 				stats.MutualInfo,
 				stats.NormalizedMI,
 				stats.DeltaLogLoss,
+				stats.PnLSkew,
+				stats.PnLKurtosisExcess,
+				stats.BreakevenBps,
+				stats.BreakevenP5,
+				stats.BreakevenP95,
+				stats.ICP5,
+				stats.ICP95,
 			)
 		}
 		fmt.Fprintf(w, "\n")
 	}
 
+	// 1a) Top table: each model at its best horizon
+	ranking := rankModels(modelNames, allStats, results, trainFrac)
+	writeTopTable(w, ranking, TopTableRows)
+
+	// 1b) Decile return curve with standard errors (bps)
+	fmt.Fprintf(w, "\n\n# Decile return curve OOS: mean bps (±SEM), T = mean/SEM of the extreme deciles; SD rows = within-decile return std (bps); HIT rows = hit rate per decile\n")
+	fmt.Fprintf(w, "MODEL\tHORIZON\tD0\tD1\tD2\tD3\tD4\tD5\tD6\tD7\tD8\tD9\tT(D0)\tT(D9)\n")
+	fmt.Fprintf(w, "-----\t-------\t--\t--\t--\t--\t--\t--\t--\t--\t--\t--\t-----\t-----\n")
+
+	for mIdx, name := range modelNames {
+		for hIdx, hName := range HorizonLabels {
+			stats := allStats[mIdx][hIdx]
+			if stats.TestCount == 0 {
+				continue
+			}
+			fmt.Fprintf(w, "%s\t%s", name, hName)
+			for d := range stats.DecileMean {
+				fmt.Fprintf(w, "\t%+.1f(±%.1f)", stats.DecileMean[d]*1e4, stats.DecileSEM[d]*1e4)
+			}
+			fmt.Fprintf(w, "\t%+.2f\t%+.2f\n", decileT(stats, 0), decileT(stats, 9))
+
+			fmt.Fprintf(w, "%s\t%s SD", name, hName)
+			for d := range stats.DecileStd {
+				fmt.Fprintf(w, "\t%.1f", stats.DecileStd[d]*1e4)
+			}
+			fmt.Fprintf(w, "\n")
+
+			fmt.Fprintf(w, "%s\t%s HIT", name, hName)
+			for d := range stats.DecileHitRate {
+				fmt.Fprintf(w, "\t%.3f", stats.DecileHitRate[d])
+			}
+			fmt.Fprintf(w, "\n")
+		}
+		fmt.Fprintf(w, "\n")
+	}
+
+	// 1c) Trade profile of the sign strategy (only with -verbose)
+	if Verbose {
+		fmt.Fprintf(w, "\n\n# Trade profile OOS, sign(signal) strategy: does the edge come from frequency (WinRate) or magnitude (Win/Loss)?\n")
+		fmt.Fprintf(w, "MODEL\tHORIZON\tAvgTrade(bps)\tAvgWin(bps)\tAvgLoss(bps)\tWin/Loss\tWinRate\tProfitFactor\tMaxConsecWin\tMaxConsecLoss\n")
+		fmt.Fprintf(w, "-----\t-------\t-------------\t-----------\t------------\t--------\t-------\t------------\t------------\t-------------\n")
+		for mIdx, name := range modelNames {
+			for hIdx, hName := range HorizonLabels {
+				stats := allStats[mIdx][hIdx]
+				if stats.TestCount == 0 {
+					continue
+				}
+				fmt.Fprintf(w, "%s\t%s\t%+.2f\t%+.2f\t%+.2f\t%.2f\t%.3f\t%.3f\t%d\t%d\n",
+					name, hName,
+					stats.AvgTrade*1e4, stats.AvgWin*1e4, stats.AvgLoss*1e4, stats.WinLossRatio,
+					stats.WinRate, stats.ProfitFactor, stats.MaxConsecWin, stats.MaxConsecLoss)
+			}
+			fmt.Fprintf(w, "\n")
+		}
+	}
+
 	// 2) Rolling OOS metrics on the test segment
 	fmt.Fprintf(w, "\n\n# Rolling OOS metrics (test segment only)\n")
 	fmt.Fprintf(w, "MODEL\tHORIZON\tWIN\tCount\tPearsonIC\tSpearmanIC\tHitRate\tSharpe\n")
@@ -285,6 +806,42 @@ func RunTestForSymbol(sym string) {
 		fmt.Fprintf(w, "\n")
 	}
 
+	// 2b) Trailing 30-day OOS windows: edge decay over the test period
+	const decayWindowDays = 30
+	fmt.Fprintf(w, "\n\n# Rolling %d-day OOS windows (daily IC mean, annualized daily-PnL Sharpe)\n", decayWindowDays)
+	fmt.Fprintf(w, "MODEL\tHORIZON\tWindows\tFirstEnd\tLastEnd\tIC(first)\tIC(last)\tSharpe(first)\tSharpe(last)\tSharpe(min)\tSharpe(max)\n")
+	fmt.Fprintf(w, "-----\t-------\t-------\t--------\t-------\t---------\t--------\t-------------\t------------\t-----------\t-----------\n")
+	var decayCharts []string
+	for mIdx, name := range modelNames {
+		for hIdx, hName := range HorizonLabels {
+			data := results[hIdx][mIdx]
+			roll := RollingDayWindowsOOS(data.Times, data.Feats, data.Targs, trainFrac, decayWindowDays)
+			if len(roll) == 0 {
+				fmt.Fprintf(w, "%s\t%s\t0\t-\t-\t\t\t\t\t\t\n", name, hName)
+				continue
+			}
+			sharpes := make([]float64, len(roll))
+			lo, hi := roll[0].Sharpe, roll[0].Sharpe
+			for i, r := range roll {
+				sharpes[i] = r.Sharpe
+				lo = math.Min(lo, r.Sharpe)
+				hi = math.Max(hi, r.Sharpe)
+			}
+			first, last := roll[0], roll[len(roll)-1]
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%.4f\t%.4f\t%.2f\t%.2f\t%.2f\t%.2f\n",
+				name, hName, len(roll), first.PeriodEnd, last.PeriodEnd,
+				first.MeanIC, last.MeanIC, first.Sharpe, last.Sharpe, lo, hi)
+
+			decayCharts = append(decayCharts, fmt.Sprintf("%s %s: rolling Sharpe, %s .. %s", name, hName, first.PeriodEnd, last.PeriodEnd))
+			decayCharts = append(decayCharts, asciiChart(sharpes, 5)...)
+			decayCharts = append(decayCharts, "")
+		}
+		fmt.Fprintf(w, "\n")
+	}
+	for _, line := range decayCharts {
+		fmt.Fprintf(w, "%s\n", line)
+	}
+
 	// 3) Volatility regime OOS metrics
 	fmt.Fprintf(w, "\n\n# Volatility regime OOS metrics (test segment only)\n")
 	fmt.Fprintf(w, "MODEL\tHORIZON\tREGIME\tCount\tPearsonIC\tSpearmanIC\tHitRate\tSharpe\n")
@@ -351,6 +908,532 @@ func RunTestForSymbol(sym string) {
 		fmt.Fprintf(w, "\n")
 	}
 
+	// 5) Signal-age OOS metrics (samples since last sign change)
+	fmt.Fprintf(w, "\n\n# Signal age OOS metrics (test segment only, age = samples since last sign flip)\n")
+	fmt.Fprintf(w, "MODEL\tHORIZON\tAGE\tCount\tPearsonIC\tSpearmanIC\tHitRate\tSharpe\n")
+	fmt.Fprintf(w, "-----\t-------\t---\t-----\t---------\t-----------\t-------\t------\n")
+
+	for mIdx, name := range modelNames {
+		for hIdx, hName := range HorizonLabels {
+			data := results[hIdx][mIdx]
+			if len(data.Feats) == 0 {
+				continue
+			}
+			ages := SignalAgeMetricsOOS(data.Times, data.Feats, data.Targs, trainFrac)
+			for _, am := range ages {
+				if am.Count == 0 {
+					continue
+				}
+				fmt.Fprintf(
+					w,
+					"%s\t%s\t%s\t%d\t%.4f\t%.4f\t%.3f\t%.3f\n",
+					name,
+					hName,
+					am.Name,
+					am.Count,
+					am.PearsonIC,
+					am.SpearmanIC,
+					am.HitRate,
+					am.Sharpe,
+				)
+			}
+		}
+		fmt.Fprintf(w, "\n")
+	}
+
+	// 6) Cross-horizon consistency of daily ICs (shortest horizon vs each longer one)
+	fmt.Fprintf(w, "\n\n# Cross-horizon consistency (test segment only, daily Pearson IC)\n")
+	fmt.Fprintf(w, "MODEL\tPAIR\tDays\tSignAgree\tRankCorr\n")
+	fmt.Fprintf(w, "-----\t----\t----\t---------\t--------\n")
+
+	dailyICs := make([][]map[int64]float64, len(models))
+	for mIdx, name := range modelNames {
+		daily := make([]map[int64]float64, len(HorizonLabels))
+		for hIdx := range HorizonLabels {
+			data := results[hIdx][mIdx]
+			daily[hIdx] = DailyICOOS(data.Times, data.Feats, data.Targs, trainFrac)
+		}
+		dailyICs[mIdx] = daily
+		for hIdx := 1; hIdx < len(HorizonLabels); hIdx++ {
+			agree, rc, days := CrossHorizonConsistency(daily[0], daily[hIdx])
+			if days == 0 {
+				continue
+			}
+			fmt.Fprintf(
+				w,
+				"%s\t%s~%s\t%d\t%.3f\t%.3f\n",
+				name,
+				HorizonLabels[0],
+				HorizonLabels[hIdx],
+				days,
+				agree,
+				rc,
+			)
+		}
+		fmt.Fprintf(w, "\n")
+	}
+
+	// 6a) Alpha half-life from the decay of daily IC across horizons of one unit
+	for _, unit := range []HorizonUnit{HorizonTime, HorizonTicks, HorizonDollar} {
+		var hIdxs []int
+		var hs []float64
+		for hIdx, h := range Horizons {
+			if h.Unit == unit {
+				hIdxs = append(hIdxs, hIdx)
+				hs = append(hs, h.Value)
+			}
+		}
+		if len(hs) < 3 {
+			continue
+		}
+		scale, suffix := 1.0, "t"
+		switch unit {
+		case HorizonTime:
+			scale, suffix = 60*1000, "min"
+		case HorizonDollar:
+			scale, suffix = 1e6, "M$"
+		}
+		fmt.Fprintf(w, "\n\n# Alpha half-life OOS: per day, log IC regressed on horizon over positive ICs (%d horizons); days with <2 positive ICs or no decay are not fitted\n", len(hs))
+		fmt.Fprintf(w, "MODEL\tDays\tFitted\tMeanHL(%s)\tMedianHL(%s)\n", suffix, suffix)
+		fmt.Fprintf(w, "-----\t----\t------\t----------\t------------\n")
+		for mIdx, name := range modelNames {
+			daily := make([]map[int64]float64, len(hIdxs))
+			for i, hIdx := range hIdxs {
+				daily[i] = dailyICs[mIdx][hIdx]
+			}
+			perDay := AlphaHalfLife(hs, daily)
+			var fitted []float64
+			for _, hl := range perDay {
+				if !math.IsNaN(hl) {
+					fitted = append(fitted, hl/scale)
+				}
+			}
+			if len(fitted) == 0 {
+				fmt.Fprintf(w, "%s\t%d\t0\t-\t-\n", name, len(perDay))
+				continue
+			}
+			sort.Float64s(fitted)
+			var mean float64
+			for _, hl := range fitted {
+				mean += hl
+			}
+			mean /= float64(len(fitted))
+			fmt.Fprintf(w, "%s\t%d\t%d\t%.3g\t%.3g\n", name, len(perDay), len(fitted), mean, percentileSorted(fitted, 0.5))
+		}
+	}
+
+	// 6b) Split-half stability on the train segment (no OOS data consumed)
+	fmt.Fprintf(w, "\n\n# Split-half stability (train segment only): even vs odd rows within each day; RANK = Spearman of model ranks by even IC vs odd IC\n")
+	fmt.Fprintf(w, "MODEL\tHORIZON\tDays\tEvenIC\tOddIC\tHalfCorr\n")
+	fmt.Fprintf(w, "-----\t-------\t----\t------\t-----\t--------\n")
+
+	for hIdx, hName := range HorizonLabels {
+		evenICs := make([]float64, 0, len(models))
+		oddICs := make([]float64, 0, len(models))
+		for mIdx, name := range modelNames {
+			data := results[hIdx][mIdx]
+			sh := SplitHalfIS(data.Times, data.Feats, data.Targs, trainFrac)
+			if sh.Days == 0 {
+				continue
+			}
+			evenICs = append(evenICs, sh.EvenIC)
+			oddICs = append(oddICs, sh.OddIC)
+			fmt.Fprintf(w, "%s\t%s\t%d\t%.4f\t%.4f\t%.3f\n", name, hName, sh.Days, sh.EvenIC, sh.OddIC, sh.HalfCorr)
+		}
+		if len(evenICs) >= 3 {
+			fmt.Fprintf(w, "RANK\t%s\t\t\t\t%.3f\n", hName, Spearman(evenICs, oddICs))
+		}
+		fmt.Fprintf(w, "\n")
+	}
+
+	// 7) Maximum adverse excursion by signal direction (only with -mae)
+	if TrackMAE {
+		fmt.Fprintf(w, "\n\n# Maximum adverse excursion OOS (bps, entry to horizon exit)\n")
+		fmt.Fprintf(w, "MODEL\tHORIZON\tLongN\tLongAvgMAE\tLongP95MAE\tShortN\tShortAvgMAE\tShortP95MAE\n")
+		fmt.Fprintf(w, "-----\t-------\t-----\t----------\t----------\t------\t-----------\t-----------\n")
+
+		for mIdx, name := range modelNames {
+			for hIdx, hName := range HorizonLabels {
+				data := results[hIdx][mIdx]
+				if len(data.Feats) == 0 {
+					continue
+				}
+				long, short := MAEStatsOOS(data.Times, data.Feats, data.MinRs, data.MaxRs, trainFrac)
+				fmt.Fprintf(
+					w,
+					"%s\t%s\t%d\t%.1f\t%.1f\t%d\t%.1f\t%.1f\n",
+					name,
+					hName,
+					long.Count,
+					long.AvgBps,
+					long.P95Bps,
+					short.Count,
+					short.AvgBps,
+					short.P95Bps,
+				)
+			}
+			fmt.Fprintf(w, "\n")
+		}
+	}
+
+	// 7a) Winsorized IC (only with -winsor)
+	if WinsorPct > 0 {
+		fmt.Fprintf(w, "\n\n# Winsorized IC OOS: test returns clipped at the train segment's %g/%g percentiles\n", 100*WinsorPct, 100*(1-WinsorPct))
+		fmt.Fprintf(w, "MODEL\tHORIZON\tPearsonIC\tWinsorIC\tLoClip(bps)\tHiClip(bps)\tClipped%%\n")
+		fmt.Fprintf(w, "-----\t-------\t---------\t--------\t-----------\t-----------\t--------\n")
+		for mIdx, name := range modelNames {
+			for hIdx, hName := range HorizonLabels {
+				data := results[hIdx][mIdx]
+				if len(data.Feats) == 0 {
+					continue
+				}
+				ws := WinsorizedICOOS(data.Times, data.Feats, data.Targs, trainFrac, WinsorPct)
+				fmt.Fprintf(w, "%s\t%s\t%.4f\t%.4f\t%+.1f\t%+.1f\t%.2f\n",
+					name, hName, allStats[mIdx][hIdx].PearsonIC, ws.IC, ws.LoBps, ws.HiBps, 100*ws.ClippedFrac)
+			}
+			fmt.Fprintf(w, "\n")
+		}
+	}
+
+	// 7b) Signal as position, with optional cap / participation limits
+	if PositionCap > 0 || PositionMaxChange > 0 {
+		key := ""
+		if PositionCap > 0 {
+			key += fmt.Sprintf("@cap%g", PositionCap)
+		}
+		if PositionMaxChange > 0 {
+			key += fmt.Sprintf("@dmax%g", PositionMaxChange)
+		}
+		fmt.Fprintf(w, "\n\n# Signal as position OOS (units of train-segment std, flat at each day end); %s rows apply the limits, Δ = share removed by them\n", key)
+		fmt.Fprintf(w, "MODEL\tHORIZON\tSumPnL(bps)\tTurnover\tPnL/Turn(bps)\tΔPnL%%\tΔTurn%%\n")
+		fmt.Fprintf(w, "-----\t-------\t-----------\t--------\t-------------\t------\t------\n")
+		for mIdx, name := range modelNames {
+			for hIdx, hName := range HorizonLabels {
+				data := results[hIdx][mIdx]
+				if len(data.Feats) == 0 {
+					continue
+				}
+				raw, lim := PositionPnLOOS(data.Times, data.Feats, data.Targs, trainFrac, PositionCap, PositionMaxChange)
+				fmt.Fprintf(w, "%s\t%s\t%+.1f\t%.1f\t%+.3f\t\t\n", name, hName, raw.SumPnLBps, raw.Turnover, raw.PnLPerTurnBps())
+				fmt.Fprintf(w, "%s\t%s%s\t%+.1f\t%.1f\t%+.3f\t%.1f\t%.1f\n", name, hName, key,
+					lim.SumPnLBps, lim.Turnover, lim.PnLPerTurnBps(),
+					pctRemoved(raw.SumPnLBps, lim.SumPnLBps), pctRemoved(raw.Turnover, lim.Turnover))
+			}
+			fmt.Fprintf(w, "\n")
+		}
+	}
+
+	// 7c) Breakeven net of square-root impact (only with -impact-c)
+	if ImpactCoefBps > 0 {
+		fmt.Fprintf(w, "\n\n# Sqrt impact OOS: cost = %.2f bps * sqrt(%.0f / dollar volume of the last %ds), charged on entry and exit\n", ImpactCoefBps, ImpactNotional, ImpactWindowSec)
+		fmt.Fprintf(w, "MODEL\tHORIZON\tTrades\tGrossBE(bps)\tImpactRT(bps)\tImpactP95(bps)\tNetBE(bps)\n")
+		fmt.Fprintf(w, "-----\t-------\t------\t------------\t-------------\t--------------\t----------\n")
+		for mIdx, name := range modelNames {
+			for hIdx, hName := range HorizonLabels {
+				data := results[hIdx][mIdx]
+				if len(data.Feats) == 0 {
+					continue
+				}
+				is := ImpactStatsOOS(data.Times, data.Feats, data.Targs, data.Imp, trainFrac)
+				fmt.Fprintf(w, "%s\t%s\t%d\t%+.2f\t%.2f\t%.2f\t%+.2f\n",
+					name, hName, is.Trades, is.GrossBps, is.CostBps, is.CostP95Bps, is.NetBps)
+			}
+			fmt.Fprintf(w, "\n")
+		}
+	}
+
+	// 7d) Maker-fill simulation (only with -maker)
+	if MakerSim {
+		fmt.Fprintf(w, "\n\n# Maker fills OOS: limit at the sample price in the signal direction, filled if a trade prints at/through it within %ds; alpha measured from the fill to the horizon exit; fee %+.2f bps (negative = rebate)\n", MakerFillSec, MakerFeeBps)
+		thrCols := ""
+		if MakerThreshold > 0 {
+			fmt.Fprintf(w, "# Trig/FillRate @%gσ: samples with |signal - train mean| > %g train std, and the share of those whose entry was attainable\n", MakerThreshold, MakerThreshold)
+			thrCols = fmt.Sprintf("\tTrig@%gσ\tFillRate@%gσ", MakerThreshold, MakerThreshold)
+		}
+		fmt.Fprintf(w, "MODEL\tHORIZON\tSignals\tFillRate\tAlpha(bps)\tNet(bps)\tTakerBE(bps)%s\n", thrCols)
+		fmt.Fprintf(w, "-----\t-------\t-------\t--------\t----------\t--------\t------------%s\n", strings.Repeat("\t--------", strings.Count(thrCols, "\t")))
+		for mIdx, name := range modelNames {
+			for hIdx, hName := range HorizonLabels {
+				data := results[hIdx][mIdx]
+				if len(data.Feats) == 0 {
+					continue
+				}
+				ms := MakerStatsOOS(data.Times, data.Feats, data.MkBuy, data.MkSel, trainFrac)
+				fmt.Fprintf(w, "%s\t%s\t%d\t%.3f\t%+.2f\t%+.2f\t%+.2f",
+					name, hName, ms.Signals, ms.FillRate, ms.AlphaBps, ms.AlphaBps-MakerFeeBps,
+					allStats[mIdx][hIdx].BreakevenBps)
+				if MakerThreshold > 0 {
+					trig, fr := ThresholdFillRateOOS(data.Feats, data.MkBuy, data.MkSel, trainFrac, MakerThreshold)
+					fmt.Fprintf(w, "\t%d\t%.3f", trig, fr)
+				}
+				fmt.Fprintf(w, "\n")
+			}
+			fmt.Fprintf(w, "\n")
+		}
+	}
+
+	// 8) External signal alignment, one row per day with gaps
+	if extSeries != nil {
+		days := make([]int64, 0, len(extAlign.Days))
+		var rows, missing, misaligned int
+		for d, a := range extAlign.Days {
+			days = append(days, d)
+			rows += a.Rows
+			missing += a.Missing
+			misaligned += a.Misaligned
+		}
+		sort.Slice(days, func(i, j int) bool { return days[i] < days[j] })
+
+		fmt.Fprintf(w, "\n\n# External signal %s (align=%s): %d rows, %d missing, %d misaligned updates; days with gaps below\n", extSeries.Name, SignalAlign, rows, missing, misaligned)
+		fmt.Fprintf(w, "DAY\tRows\tMissing\tSignals\tMisaligned\n")
+		fmt.Fprintf(w, "---\t----\t-------\t-------\t----------\n")
+		for _, d := range days {
+			a := extAlign.Days[d]
+			if a.Missing == 0 && a.Misaligned == 0 {
+				continue
+			}
+			fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\n", time.UnixMilli(d*dayMillis).UTC().Format("2006-01-02"), a.Rows, a.Missing, a.Signals, a.Misaligned)
+		}
+		if missing > 0 || misaligned > 0 {
+			fmt.Printf("[%s] WARN: external signal has %d missing rows and %d misaligned updates (see report)\n", sym, missing, misaligned)
+		}
+	}
+
+	// 9) Alternative IS/OOS boundaries (-oos-dates)
+	for _, d := range OOSDates {
+		writeBoundarySection(w, d, modelNames, results)
+	}
+
 	w.Flush()
-	fmt.Printf("Done. [%s] Processed %d days in %s. OOS report saved to %s\n", sym, processed.Load(), time.Since(start), filename)
+
+	jsonName := fmt.Sprintf("Continuous_Algo_Summary_%s.json", sym)
+	if err := writeJSONSummary(jsonName, sym, modelNames, allStats, results, ranking, trainFrac); err != nil {
+		fmt.Printf("[%s] ERROR: could not write JSON summary %s: %v\n", sym, jsonName, err)
+	}
+
+	if HTMLDashboard {
+		htmlName := fmt.Sprintf("Continuous_Algo_Dashboard_%s.html", sym)
+		if err := writeDashboard(htmlName, sym, modelNames, allStats, results, trainFrac); err != nil {
+			fmt.Printf("[%s] ERROR: could not write dashboard %s: %v\n", sym, htmlName, err)
+		} else {
+			jsonName += ", " + htmlName
+		}
+	}
+
+	printSkippedDays(sym, failedDays, shortDays)
+	printDayTimings(sym, timings)
+	if testTiming != nil {
+		testTiming.WriteSymbol(sym, timings)
+	}
+
+	fmt.Printf("Done. [%s] Processed %d days in %s (mem: %s). OOS report saved to %s (+ %s)\n", sym, processed.Load(), time.Since(start), getMemUsage(), filename, jsonName)
+}
+
+// writeBoundarySection repeats the core summary with the test segment
+// starting at boundary instead of the default train fraction. The samples
+// are the ones already streamed, so no day is decoded twice.
+func writeBoundarySection(w io.Writer, boundary time.Time, modelNames []string, results [][]*ResultContainer) {
+	label := boundary.Format("2006-01-02")
+	cut := float64(boundary.UnixMilli())
+	fmt.Fprintf(w, "\n\n# [OOS from %s] core summary with the test segment starting at %s\n", label, label)
+	fmt.Fprintf(w, "MODEL\tHORIZON\tTrainN\tTestN\tPearsonIC\tSpearmanIC\tIC_T(eff)\tDayIC_T\tHitRate\tAnnSharpe\tPSR\tBE(bps)\tIC_P5\tIC_P95\n")
+	fmt.Fprintf(w, "-----\t-------\t------\t-----\t---------\t-----------\t---------\t-------\t-------\t---------\t---\t-------\t-----\t------\n")
+	for mIdx, name := range modelNames {
+		for hIdx, hName := range HorizonLabels {
+			data := results[hIdx][mIdx]
+			n := len(data.Times)
+			k := sort.SearchFloat64s(data.Times, cut) // containers are time-sorted
+			if k < 20 || n-k < 30 {
+				fmt.Fprintf(w, "%s\t%s\t%d\t%d\t(too few samples on one side of %s)\n", name, hName, k, n-k, label)
+				continue
+			}
+			// Half a sample of slack so trainCount lands exactly on k.
+			stats := AnalyzeFullSuiteOOS(data.Times, data.Feats, data.Targs, (float64(k)+0.5)/float64(n))
+			fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%.4f\t%.4f\t%.2f\t%.2f\t%.3f\t%.2f\t%.3f\t%+.2f\t%.4f\t%.4f\n",
+				name, hName, stats.TrainCount, stats.TestCount, stats.PearsonIC, stats.SpearmanIC,
+				stats.ICTEff, stats.DailyICT, stats.HitRate, stats.AnnualizedSharpe, stats.PSR,
+				stats.BreakevenBps, stats.ICP5, stats.ICP95)
+		}
+		fmt.Fprintf(w, "\n")
+	}
+}
+
+// writeFeatureStats prints each model's sampled feature distribution and
+// flags degenerate models (constant, or almost always zero). Features are
+// identical across horizons, so the first horizon's containers are used.
+// Dist is a featureHistBins-bin histogram over mean ± 4 std (tails land in
+// the edge bins); DayTV is the largest total-variation distance between one
+// day's histogram and the whole sample's, a cheap drift detector.
+func writeFeatureStats(w io.Writer, modelNames []string, results [][]*ResultContainer, nanCount, infCount []int64) {
+	fmt.Fprintf(w, "# Feature stats (all retained samples; NaN/Inf were zeroed and are included as zeros; Dist = histogram over mean±4sd; DayTV = worst day-vs-all total variation)\n")
+	fmt.Fprintf(w, "MODEL\tSamples\tMean\tStd\tNonZero%%\tNaN%%\tInf%%\tDist\tDayTV\tDay\tFLAG\n")
+	fmt.Fprintf(w, "-----\t-------\t----\t---\t--------\t----\t----\t----\t-----\t---\t----\n")
+	for mIdx, name := range modelNames {
+		rc := results[0][mIdx]
+		feats := rc.Feats
+		n := len(feats)
+		if n == 0 {
+			fmt.Fprintf(w, "%s\t0\t-\t-\t-\t-\t-\t-\t-\t-\tEMPTY\n", name)
+			continue
+		}
+		var sum float64
+		nonZero := 0
+		for _, f := range feats {
+			sum += f
+			if f != 0 {
+				nonZero++
+			}
+		}
+		mean, sd := sum/float64(n), stdDev(feats)
+		nzPct := 100 * float64(nonZero) / float64(n)
+		flag := ""
+		switch {
+		case sd == 0:
+			flag = "CONSTANT"
+		case nzPct < 1:
+			flag = "MOSTLY_ZERO"
+		}
+
+		dist, dayTV, tvDay := "-", "-", "-"
+		if sd > 0 {
+			lo, hi := mean-4*sd, mean+4*sd
+			all := featureHist(feats, lo, hi)
+			shares := make([]float64, featureHistBins)
+			for b, c := range all {
+				shares[b] = float64(c)
+			}
+			dist = sparkline(shares)
+
+			worst, worstStart := -1.0, 0
+			forEachDay(rc.Times, func(start, end int) {
+				if end-start < 30 {
+					return // too few samples for a stable histogram
+				}
+				if tv := histTV(featureHist(feats[start:end], lo, hi), all); tv > worst {
+					worst, worstStart = tv, start
+				}
+			})
+			if worst >= 0 {
+				dayTV = fmt.Sprintf("%.3f", worst)
+				tvDay = time.UnixMilli(int64(rc.Times[worstStart])).UTC().Format("2006-01-02")
+			}
+		}
+
+		fmt.Fprintf(w, "%s\t%d\t%.6g\t%.6g\t%.2f\t%.3f\t%.3f\t%s\t%s\t%s\t%s\n",
+			name, n, mean, sd, nzPct,
+			100*float64(nanCount[mIdx])/float64(n), 100*float64(infCount[mIdx])/float64(n),
+			dist, dayTV, tvDay, flag)
+	}
+	fmt.Fprintf(w, "\n")
+}
+
+const featureHistBins = 32
+
+// featureHist counts xs into featureHistBins equal bins over [lo, hi];
+// values outside fall into the edge bins.
+func featureHist(xs []float64, lo, hi float64) []int {
+	h := make([]int, featureHistBins)
+	scale := featureHistBins / (hi - lo)
+	for _, x := range xs {
+		b := int((x - lo) * scale)
+		if b < 0 {
+			b = 0
+		} else if b >= featureHistBins {
+			b = featureHistBins - 1
+		}
+		h[b]++
+	}
+	return h
+}
+
+// histTV is the total-variation distance between two histograms' shares.
+func histTV(a, b []int) float64 {
+	var na, nb int
+	for i := range a {
+		na += a[i]
+		nb += b[i]
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	var d float64
+	for i := range a {
+		d += math.Abs(float64(a[i])/float64(na) - float64(b[i])/float64(nb))
+	}
+	return d / 2
+}
+
+// pctRemoved is the percentage of before that after no longer has.
+func pctRemoved(before, after float64) float64 {
+	if before == 0 {
+		return 0
+	}
+	return 100 * (before - after) / math.Abs(before)
+}
+
+// asciiChart renders vals as a small column chart, height rows tall, with
+// the value range on the left and '-' marking zero when it is in range.
+func asciiChart(vals []float64, height int) []string {
+	if len(vals) == 0 || height < 2 {
+		return nil
+	}
+	lo, hi := vals[0], vals[0]
+	for _, v := range vals {
+		lo = math.Min(lo, v)
+		hi = math.Max(hi, v)
+	}
+	if hi == lo {
+		hi = lo + 1
+	}
+	level := func(v float64) int {
+		return int(math.Round((v - lo) / (hi - lo) * float64(height-1)))
+	}
+	zero := -1
+	if lo <= 0 && hi >= 0 {
+		zero = level(0)
+	}
+
+	lines := make([]string, height)
+	row := make([]byte, len(vals))
+	for r := height - 1; r >= 0; r-- {
+		for i, v := range vals {
+			switch {
+			case level(v) == r:
+				row[i] = '*'
+			case r == zero:
+				row[i] = '-'
+			default:
+				row[i] = ' '
+			}
+		}
+		label := ""
+		switch r {
+		case height - 1:
+			label = fmt.Sprintf("%+7.2f ", hi)
+		case 0:
+			label = fmt.Sprintf("%+7.2f ", lo)
+		default:
+			label = "        "
+		}
+		lines[height-1-r] = label + "|" + string(row)
+	}
+	return lines
+}
+
+// printProgress rewrites a single status line: done/total, rate and ETA.
+func printProgress(prefix string, done, total int64, start time.Time) {
+	elapsed := time.Since(start).Seconds()
+	pct := 0.0
+	if total > 0 {
+		pct = 100 * float64(done) / float64(total)
+	}
+	rate := 0.0
+	if elapsed > 0 {
+		rate = float64(done) / elapsed
+	}
+	eta := "-"
+	if rate > 0 && done < total {
+		eta = time.Duration(float64(total-done) / rate * float64(time.Second)).Round(time.Second).String()
+	}
+	fmt.Printf("\r[%s] %d/%d days (%.1f%%) | %.1f days/s | ETA: %s   ", prefix, done, total, pct, rate, eta)
 }
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+// dayTiming is the wall time one worker spent on one day: Load covers the
+// blob read and decode, Stream covers RunStream (all models + labeling).
+type dayTiming struct {
+	Date         time.Time
+	Rows         int
+	Load, Stream time.Duration
+}
+
+func (d dayTiming) MRowsPerSec() float64 {
+	if s := (d.Load + d.Stream).Seconds(); s > 0 {
+		return float64(d.Rows) / s / 1e6
+	}
+	return 0
+}
+
+// printDayTimings prints min/median/max of the per-day phases and
+// throughput, then the slowest days by throughput.
+func printDayTimings(sym string, ts []dayTiming) {
+	if len(ts) == 0 {
+		return
+	}
+	const slowest = 5
+
+	ms := func(f func(dayTiming) float64) (lo, med, hi float64) {
+		vals := make([]float64, len(ts))
+		for i, t := range ts {
+			vals[i] = f(t)
+		}
+		sort.Float64s(vals)
+		return vals[0], vals[len(vals)/2], vals[len(vals)-1]
+	}
+
+	fmt.Printf("[%s] Per-day timing over %d days (%d workers):\n", sym, len(ts), CPUThreads)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  METRIC\tMIN\tMEDIAN\tMAX")
+	for _, row := range []struct {
+		name, format string
+		f            func(dayTiming) float64
+	}{
+		{"rows", "%.0f", func(t dayTiming) float64 { return float64(t.Rows) }},
+		{"load_ms", "%.2f", func(t dayTiming) float64 { return float64(t.Load.Microseconds()) / 1e3 }},
+		{"stream_ms", "%.2f", func(t dayTiming) float64 { return float64(t.Stream.Microseconds()) / 1e3 }},
+		{"Mrows/s", "%.2f", dayTiming.MRowsPerSec},
+	} {
+		lo, med, hi := ms(row.f)
+		fmt.Fprintf(w, "  %s\t"+row.format+"\t"+row.format+"\t"+row.format+"\n", row.name, lo, med, hi)
+	}
+	w.Flush()
+
+	byRate := append([]dayTiming(nil), ts...)
+	sort.Slice(byRate, func(i, j int) bool { return byRate[i].MRowsPerSec() < byRate[j].MRowsPerSec() })
+	if len(byRate) > slowest {
+		byRate = byRate[:slowest]
+	}
+	fmt.Printf("  slowest:")
+	for _, t := range byRate {
+		fmt.Printf(" %s (%.2f Mrows/s, %d rows)", t.Date.Format("2006-01-02"), t.MRowsPerSec(), t.Rows)
+	}
+	fmt.Println()
+}
+
+// dayTimingExporter writes one CSV row per (symbol, day) for -timing-csv.
+type dayTimingExporter struct {
+	f  *os.File
+	cw *csv.Writer
+}
+
+func newDayTimingExporter(path string) (*dayTimingExporter, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	cw := csv.NewWriter(f)
+	cw.Write([]string{"symbol", "date", "rows", "load_ms", "stream_ms", "mrows_per_sec"})
+	return &dayTimingExporter{f: f, cw: cw}, nil
+}
+
+// WriteSymbol exports sym's days in date order.
+func (e *dayTimingExporter) WriteSymbol(sym string, ts []dayTiming) {
+	sorted := append([]dayTiming(nil), ts...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Date.Before(sorted[j].Date) })
+	ff := func(v float64) string { return strconv.FormatFloat(v, 'f', 3, 64) }
+	for _, t := range sorted {
+		e.cw.Write([]string{
+			sym,
+			t.Date.Format("2006-01-02"),
+			strconv.Itoa(t.Rows),
+			ff(float64(t.Load.Microseconds()) / 1e3),
+			ff(float64(t.Stream.Microseconds()) / 1e3),
+			ff(t.MRowsPerSec()),
+		})
+	}
+}
+
+func (e *dayTimingExporter) Close() error {
+	e.cw.Flush()
+	if err := e.cw.Error(); err != nil {
+		e.f.Close()
+		return err
+	}
+	return e.f.Close()
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+)
+
+// RunTrace replays one day like RunReplay but, for trades [from, from+rows),
+// also dumps every scalar field of each model's internal state (EW sums,
+// variances, last price, warm-up flags, ...) next to its output. State is
+// read by reflection after each Update, so the models themselves carry no
+// tracing code and the normal pipelines pay nothing for it.
+func RunTrace(sym, day, models, out string, from, rows int) {
+	fmt.Println(">>> MODEL STATE TRACE <<<")
+
+	keep, err := mlExportModelFilter(models)
+	if err != nil {
+		fmt.Println("trace:", err)
+		return
+	}
+	var ms []ContinuousModel
+	for _, m := range GetContinuousModels() {
+		if keep(m.Name()) {
+			m.Reset()
+			ms = append(ms, m)
+		}
+	}
+
+	cols, err := loadReplayDay(sym, day)
+	if err != nil {
+		fmt.Printf("[%s] %v\n", sym, err)
+		return
+	}
+	if from < 0 || from >= cols.Count {
+		fmt.Printf("[%s] -from-row %d outside the day's %d trades\n", sym, from, cols.Count)
+		return
+	}
+	end := cols.Count
+	if rows > 0 && from+rows < end {
+		end = from + rows
+	}
+	if out == "" {
+		out = fmt.Sprintf("trace_%s_%s.csv", sym, day)
+	}
+
+	f, err := os.Create(out)
+	if err != nil {
+		fmt.Println("trace:", err)
+		return
+	}
+	defer f.Close()
+	bw := bufio.NewWriter(f)
+	cw := csv.NewWriter(bw)
+
+	header := []string{"row", "ts_ms", "price", "qty", "sign"}
+	fields := make([][]traceField, len(ms))
+	for j, m := range ms {
+		header = append(header, m.Name())
+		fields[j] = traceFields(modelState(m))
+		for _, tf := range fields[j] {
+			header = append(header, m.Name()+"."+tf.name)
+		}
+	}
+	cw.Write(header)
+
+	row := make([]string, 0, len(header))
+	sided := sidedModels(ms)
+	lastT := cols.Times[0]
+	for i := 0; i < end; i++ {
+		t := cols.Times[i]
+		dt := float64(t-lastT) / 1000.0
+		if dt < 0 {
+			dt = 0
+		}
+		lastT = t
+		for _, s := range sided {
+			s.SetSide(cols.Signs[i])
+		}
+
+		if i < from {
+			for _, m := range ms {
+				m.Update(dt, cols.Prices[i], cols.Qtys[i])
+			}
+			continue
+		}
+		row = append(row[:0],
+			strconv.Itoa(i),
+			strconv.FormatInt(t, 10),
+			strconv.FormatFloat(cols.Prices[i], 'g', -1, 64),
+			strconv.FormatFloat(cols.Qtys[i], 'g', -1, 64),
+			strconv.Itoa(int(cols.Signs[i])),
+		)
+		for j, m := range ms {
+			row = append(row, strconv.FormatFloat(m.Update(dt, cols.Prices[i], cols.Qtys[i]), 'g', -1, 64))
+			for _, tf := range fields[j] {
+				row = append(row, tf.format())
+			}
+		}
+		cw.Write(row)
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		fmt.Println("trace:", err)
+		return
+	}
+	if err := bw.Flush(); err != nil {
+		fmt.Println("trace:", err)
+		return
+	}
+	fmt.Printf("Symbol: %s | Day: %s | Rows: %d-%d of %d | Models: %d -> %s\n", sym, day, from, end-1, cols.Count, len(ms), out)
+}
+
+// traceField is one scalar field of a model's state struct.
+type traceField struct {
+	name string
+	v    reflect.Value
+}
+
+func (tf traceField) format() string {
+	switch tf.v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(tf.v.Float(), 'g', -1, 64)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(tf.v.Int(), 10)
+	case reflect.Bool:
+		return strconv.FormatBool(tf.v.Bool())
+	}
+	return ""
+}
+
+// traceFields lists the float, int and bool fields of the struct state
+// points to, including those of embedded structs. Slices (e.g. ring
+// buffers) are skipped.
+func traceFields(state any) []traceField {
+	v := reflect.ValueOf(state)
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	return structFields(v)
+}
+
+// structFields lists the scalar fields of struct v, descending into
+// embedded structs (zGuard, tickSide) under their promoted names.
+func structFields(v reflect.Value) []traceField {
+	var out []traceField
+	for i := 0; i < v.NumField(); i++ {
+		f, sf := v.Field(i), v.Type().Field(i)
+		switch f.Kind() {
+		case reflect.Float32, reflect.Float64, reflect.Bool,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			out = append(out, traceField{sf.Name, f})
+		case reflect.Struct:
+			if sf.Anonymous {
+				out = append(out, structFields(f)...)
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,15 @@
+package main
+
+import "testing"
+
+func TestTraceFieldsIncludeEmbedded(t *testing.T) {
+	got := make(map[string]bool)
+	for _, tf := range traceFields(NewSizeAnomaly()) {
+		got[tf.name] = true
+	}
+	for _, name := range []string{"alpha", "mean", "vr", "warm", "longVr", "clamps", "lastP", "side", "init"} {
+		if !got[name] {
+			t.Errorf("trace fields of Size_Anomaly lack %q (have %v)", name, got)
+		}
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !amd64 && !arm64
+
+package main
+
+// mapColumns copies the columns out with decodeColumns. Targets other than
+// amd64/arm64 may be big-endian or trap on unaligned loads, so the
+// zero-copy view is not safe there.
+func (tb *TradeBlock) mapColumns(raw []byte, h tbHeader) {
+	tb.decodeColumns(raw, h)
+}
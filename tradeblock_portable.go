@@ -0,0 +1,38 @@
+package main
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// decodeColumns decodes the little-endian columns into fresh slices. It is
+// the portable counterpart of the zero-copy view and works on any target;
+// mapColumns uses it where that view is not safe.
+func (tb *TradeBlock) decodeColumns(raw []byte, h tbHeader) {
+	count := tb.Count
+	u64 := func(off uint32, n int) []uint64 {
+		out := make([]uint64, n)
+		for i := range out {
+			out[i] = binary.LittleEndian.Uint64(raw[int(off)+8*i:])
+		}
+		return out
+	}
+	f64 := func(off uint32) []float64 {
+		out := make([]float64, count)
+		for i := range out {
+			out[i] = math.Float64frombits(binary.LittleEndian.Uint64(raw[int(off)+8*i:]))
+		}
+		return out
+	}
+
+	tb.AggTradeIDs = u64(h.OffAgg, count)
+	tb.Prices = f64(h.OffPrice)
+	tb.Quantities = f64(h.OffQty)
+	tb.FirstTradeIDs = u64(h.OffFirst, count)
+	tb.LastTradeIDs = u64(h.OffLast, count)
+	tb.Times = make([]int64, count)
+	for i := range tb.Times {
+		tb.Times[i] = int64(binary.LittleEndian.Uint64(raw[int(h.OffTime)+8*i:]))
+	}
+	tb.BuyerBits = u64(h.OffBits, int(h.BitWords))
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestMapColumnsMatchesDecode(t *testing.T) {
+	rng := rand.New(rand.NewSource(11))
+	for trial := 0; trial < 50; trial++ {
+		n := 1 + rng.Intn(300)
+		blob := encodeTBV1(make([]int64, n), make([]float64, n), make([]float64, n), nil)
+		h, err := parseTBHeader(blob, uint64(len(blob)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Random bytes in every column, including NaN/Inf float patterns
+		// and the padding bits of the last bitset word.
+		rng.Read(blob[TBHdrSize:])
+
+		// Shift the blob so the columns are not 8-byte aligned.
+		shift := rng.Intn(8)
+		raw := make([]byte, shift+len(blob))[shift:]
+		copy(raw, blob)
+
+		mapped := &TradeBlock{Count: n}
+		mapped.mapColumns(raw, h)
+		decoded := &TradeBlock{Count: n}
+		decoded.decodeColumns(raw, h)
+
+		eqU64 := func(name string, a, b []uint64) {
+			t.Helper()
+			if len(a) != len(b) {
+				t.Fatalf("trial %d %s: len %d vs %d", trial, name, len(a), len(b))
+			}
+			for i := range a {
+				if a[i] != b[i] {
+					t.Fatalf("trial %d %s[%d]: %#x vs %#x", trial, name, i, a[i], b[i])
+				}
+			}
+		}
+		bits := func(x []float64) []uint64 {
+			out := make([]uint64, len(x))
+			for i, v := range x {
+				out[i] = math.Float64bits(v)
+			}
+			return out
+		}
+		times := func(x []int64) []uint64 {
+			out := make([]uint64, len(x))
+			for i, v := range x {
+				out[i] = uint64(v)
+			}
+			return out
+		}
+		eqU64("AggTradeIDs", mapped.AggTradeIDs, decoded.AggTradeIDs)
+		eqU64("Prices", bits(mapped.Prices), bits(decoded.Prices))
+		eqU64("Quantities", bits(mapped.Quantities), bits(decoded.Quantities))
+		eqU64("FirstTradeIDs", mapped.FirstTradeIDs, decoded.FirstTradeIDs)
+		eqU64("LastTradeIDs", mapped.LastTradeIDs, decoded.LastTradeIDs)
+		eqU64("Times", times(mapped.Times), times(decoded.Times))
+		eqU64("BuyerBits", mapped.BuyerBits, decoded.BuyerBits)
+		for i := 0; i < n; i++ {
+			if mapped.IsBuyerMaker(i) != decoded.IsBuyerMaker(i) {
+				t.Fatalf("trial %d IsBuyerMaker(%d) differs", trial, i)
+			}
+		}
+	}
+}
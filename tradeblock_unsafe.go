@@ -0,0 +1,21 @@
+//go:build amd64 || arm64
+
+package main
+
+import "unsafe"
+
+// mapColumns points the columns straight into raw. Both architectures are
+// little-endian and tolerate unaligned loads, so this is safe even when the
+// read buffer itself is not 8-byte aligned.
+func (tb *TradeBlock) mapColumns(raw []byte, h tbHeader) {
+	count := tb.Count
+	base := unsafe.Pointer(&raw[0])
+
+	tb.AggTradeIDs = unsafe.Slice((*uint64)(unsafe.Add(base, uintptr(h.OffAgg))), count)
+	tb.Prices = unsafe.Slice((*float64)(unsafe.Add(base, uintptr(h.OffPrice))), count)
+	tb.Quantities = unsafe.Slice((*float64)(unsafe.Add(base, uintptr(h.OffQty))), count)
+	tb.FirstTradeIDs = unsafe.Slice((*uint64)(unsafe.Add(base, uintptr(h.OffFirst))), count)
+	tb.LastTradeIDs = unsafe.Slice((*uint64)(unsafe.Add(base, uintptr(h.OffLast))), count)
+	tb.Times = unsafe.Slice((*int64)(unsafe.Add(base, uintptr(h.OffTime))), count)
+	tb.BuyerBits = unsafe.Slice((*uint64)(unsafe.Add(base, uintptr(h.OffBits))), int(h.BitWords))
+}
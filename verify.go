@@ -0,0 +1,286 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// verifyTol is the largest relative difference allowed between ReportStats
+// fields computed from the same samples merged in different orders.
+const verifyTol = 1e-12
+
+// RunVerify checks that the pipeline is deterministic on a sample of days of
+// sym, with blob checksums always verified. Every sampled day is decoded
+// twice and streamed three times (fresh models, a second fresh set, and the
+// first set again to exercise Reset); all outputs must match bit for bit. The sampled days are then merged in
+// forward and reverse order, as different worker schedules would, and the
+// OOS stats of every (model, horizon) must agree within verifyTol, both
+// across merge orders and between one worker and CPUThreads (at least two)
+// workers for the parallel stages such as the bootstrap. The first
+// difference of each check is printed and the process exits with status 1.
+func RunVerify(sym string, days int) {
+	start := time.Now()
+	fmt.Println(">>> DETERMINISM CHECK <<<")
+	VerifyChecksums = true
+
+	var tasks []ofiTask
+	for t := range discoverTasks(sym) {
+		tasks = append(tasks, t)
+	}
+	if len(tasks) == 0 {
+		fmt.Printf("[%s] no days found\n", sym)
+		os.Exit(1)
+	}
+	sort.Slice(tasks, func(i, j int) bool { return taskDate(tasks[i]).Before(taskDate(tasks[j])) })
+	if days < 1 || days > len(tasks) {
+		days = len(tasks)
+	}
+	// Evenly spaced over the history, first and last day included.
+	sample := make([]ofiTask, days)
+	for i := range sample {
+		k := 0
+		if days > 1 {
+			k = i * (len(tasks) - 1) / (days - 1)
+		}
+		sample[i] = tasks[k]
+	}
+	fmt.Printf("Symbol: %s | Days: %d of %d | Horizons: %v\n\n", sym, days, len(tasks), HorizonLabels)
+
+	modelsA := GetContinuousModels()
+	modelsB := GetContinuousModels()
+	modelNames := make([]string, len(modelsA))
+	for i, m := range modelsA {
+		modelNames[i] = m.Name()
+	}
+
+	failures := 0
+	var streams []StreamResult
+	var buf []byte
+	for _, task := range sample {
+		date := taskDate(task).Format("2006-01-02")
+		colsA, colsB := &DayColumns{}, &DayColumns{}
+		ok := true
+		for _, cols := range []*DayColumns{colsA, colsB} {
+			if !LoadGNCFile(SymbolRoot(sym), sym, task, &buf) {
+				ok = false
+				break
+			}
+			if _, err := InflateGNC(buf, cols); err != nil {
+				fmt.Printf("[%s] %s decode: %v\n", sym, date, err)
+				ok = false
+				break
+			}
+		}
+		if !ok {
+			failures++
+			fmt.Printf("[%s] %s FAIL load\n", sym, date)
+			continue
+		}
+		if diff := firstColumnDiff(colsA, colsB); diff != "" {
+			failures++
+			fmt.Printf("[%s] %s FAIL decode: %s\n", sym, date, diff)
+			continue
+		}
+		if hasDollarHorizon(Horizons) || ImpactCoefBps > 0 {
+			colsA.FillCumNotional()
+			colsB.FillCumNotional()
+		}
+
+		first := RunStream(colsA, modelsA)
+		fresh := RunStream(colsB, modelsB)
+		again := RunStream(colsA, modelsA)
+		dayOK := true
+		for _, run := range []struct {
+			label string
+			res   StreamResult
+		}{{"fresh models", fresh}, {"reused models", again}} {
+			if diff := firstStreamDiff(first, run.res, modelNames); diff != "" {
+				failures++
+				dayOK = false
+				fmt.Printf("[%s] %s FAIL stream (%s): %s\n", sym, date, run.label, diff)
+			}
+		}
+		if dayOK {
+			fmt.Printf("[%s] %s ok (%d trades, %d samples)\n", sym, date, colsA.Count, len(first.Times))
+		}
+		streams = append(streams, first)
+	}
+
+	// Forward vs reverse merge order, and one worker vs many.
+	const trainFrac = 0.7
+	fwd := verifyContainers(streams, false)
+	rev := verifyContainers(streams, true)
+	workers := max(CPUThreads, 2)
+	analyze := func(rc *ResultContainer, threads int) ReportStats {
+		old := CPUThreads
+		CPUThreads = threads
+		defer func() { CPUThreads = old }()
+		return AnalyzeFullSuiteOOS(rc.Times, rc.Feats, rc.Targs, trainFrac)
+	}
+	cells := 0
+	for hIdx, hName := range HorizonLabels {
+		for mIdx, name := range modelNames {
+			a := analyze(fwd[hIdx][mIdx], workers)
+			cells++
+			for _, cmp := range []struct {
+				label string
+				b     ReportStats
+			}{
+				{"merge order", analyze(rev[hIdx][mIdx], workers)},
+				{fmt.Sprintf("workers %d vs 1", workers), analyze(fwd[hIdx][mIdx], 1)},
+			} {
+				if diff := firstFieldDiff(reflect.ValueOf(a), reflect.ValueOf(cmp.b), "ReportStats"); diff != "" {
+					failures++
+					fmt.Printf("[%s] %s %s FAIL %s: %s\n", sym, name, hName, cmp.label, diff)
+				}
+			}
+		}
+	}
+	fmt.Printf("\n[%s] %d days, %d stat cells checked, %d failures in %s\n", sym, len(sample), cells, failures, time.Since(start))
+	if failures > 0 {
+		os.Exit(1)
+	}
+}
+
+// verifyContainers flattens day results into [horizon][model] containers,
+// appending the days in reverse when reverse is set, then sorts them.
+func verifyContainers(streams []StreamResult, reverse bool) [][]*ResultContainer {
+	numModels := 0
+	if len(streams) > 0 {
+		numModels = streams[0].NumModels
+	}
+	out := make([][]*ResultContainer, len(Horizons))
+	for hIdx := range out {
+		out[hIdx] = make([]*ResultContainer, numModels)
+		for mIdx := range out[hIdx] {
+			out[hIdx][mIdx] = &ResultContainer{}
+		}
+	}
+	for i := range streams {
+		sr := streams[i]
+		if reverse {
+			sr = streams[len(streams)-1-i]
+		}
+		for s, t := range sr.Times {
+			for mIdx := 0; mIdx < sr.NumModels; mIdx++ {
+				for hIdx := 0; hIdx < sr.NumHorizons; hIdx++ {
+					rc := out[hIdx][mIdx]
+					rc.Times = append(rc.Times, float64(t))
+					rc.Feats = append(rc.Feats, sr.Features[s*sr.NumModels+mIdx])
+					rc.Targs = append(rc.Targs, sr.Targets[s*sr.NumHorizons+hIdx])
+				}
+			}
+		}
+	}
+	for _, row := range out {
+		for _, rc := range row {
+			rc.SortByTime()
+		}
+	}
+	return out
+}
+
+// firstColumnDiff compares two decodes of the same blob bit for bit.
+func firstColumnDiff(a, b *DayColumns) string {
+	if a.Count != b.Count {
+		return fmt.Sprintf("count %d vs %d", a.Count, b.Count)
+	}
+	for i := 0; i < a.Count; i++ {
+		switch {
+		case a.Times[i] != b.Times[i]:
+			return fmt.Sprintf("row %d time %d vs %d", i, a.Times[i], b.Times[i])
+		case math.Float64bits(a.Prices[i]) != math.Float64bits(b.Prices[i]):
+			return fmt.Sprintf("row %d price %v vs %v", i, a.Prices[i], b.Prices[i])
+		case math.Float64bits(a.Qtys[i]) != math.Float64bits(b.Qtys[i]):
+			return fmt.Sprintf("row %d qty %v vs %v", i, a.Qtys[i], b.Qtys[i])
+		case a.Signs[i] != b.Signs[i]:
+			return fmt.Sprintf("row %d sign %d vs %d", i, a.Signs[i], b.Signs[i])
+		}
+	}
+	return ""
+}
+
+// firstStreamDiff compares two StreamResults bit for bit and names the first
+// differing sample, model or horizon.
+func firstStreamDiff(a, b StreamResult, modelNames []string) string {
+	if len(a.Times) != len(b.Times) {
+		return fmt.Sprintf("samples %d vs %d", len(a.Times), len(b.Times))
+	}
+	for s := range a.Times {
+		if a.Times[s] != b.Times[s] {
+			return fmt.Sprintf("sample %d time %d vs %d", s, a.Times[s], b.Times[s])
+		}
+	}
+	cols := []struct {
+		name  string
+		x, y  []float64
+		width int
+		names []string
+	}{
+		{"price", a.Prices, b.Prices, 1, nil},
+		{"feature", a.Features, b.Features, a.NumModels, modelNames},
+		{"target", a.Targets, b.Targets, a.NumHorizons, HorizonLabels},
+		{"min ret", a.MinRets, b.MinRets, a.NumHorizons, HorizonLabels},
+		{"max ret", a.MaxRets, b.MaxRets, a.NumHorizons, HorizonLabels},
+		{"maker buy", a.MakerBuy, b.MakerBuy, a.NumHorizons, HorizonLabels},
+		{"maker sell", a.MakerSell, b.MakerSell, a.NumHorizons, HorizonLabels},
+		{"impact", a.ImpactBps, b.ImpactBps, 1, nil},
+	}
+	for _, c := range cols {
+		if len(c.x) != len(c.y) {
+			return fmt.Sprintf("%s length %d vs %d", c.name, len(c.x), len(c.y))
+		}
+		for i := range c.x {
+			if math.Float64bits(c.x[i]) == math.Float64bits(c.y[i]) {
+				continue
+			}
+			which := ""
+			if c.names != nil {
+				which = " " + c.names[i%c.width]
+			}
+			return fmt.Sprintf("sample %d%s %s %v vs %v", i/c.width, which, c.name, c.x[i], c.y[i])
+		}
+	}
+	return ""
+}
+
+// firstFieldDiff walks two values of the same type and returns the path of
+// the first float field differing by more than verifyTol (relative). NaNs
+// compare equal to each other.
+func firstFieldDiff(a, b reflect.Value, path string) string {
+	switch a.Kind() {
+	case reflect.Float64:
+		x, y := a.Float(), b.Float()
+		if math.IsNaN(x) && math.IsNaN(y) {
+			return ""
+		}
+		if x == y || math.Abs(x-y) <= verifyTol*math.Max(1, math.Max(math.Abs(x), math.Abs(y))) {
+			return ""
+		}
+		return fmt.Sprintf("%s %v vs %v", path, x, y)
+	case reflect.Int, reflect.Int64:
+		if a.Int() != b.Int() {
+			return fmt.Sprintf("%s %d vs %d", path, a.Int(), b.Int())
+		}
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if d := firstFieldDiff(a.Field(i), b.Field(i), path+"."+a.Type().Field(i).Name); d != "" {
+				return d
+			}
+		}
+	case reflect.Slice:
+		if a.Len() != b.Len() {
+			return fmt.Sprintf("%s length %d vs %d", path, a.Len(), b.Len())
+		}
+		for i := 0; i < a.Len(); i++ {
+			if d := firstFieldDiff(a.Index(i), b.Index(i), fmt.Sprintf("%s[%d]", path, i)); d != "" {
+				return d
+			}
+		}
+	}
+	return ""
+}
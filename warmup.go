@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// RunWarmup measures how long each model needs to forget its starting
+// state. One representative day is streamed twice: cold (Reset at the
+// open, as RunStream does) and carried (state built over the previous day
+// first). It reports the trade at which the two feature paths converge for
+// good (|cold - carried| <= tol * std(carried)) and the IC of both runs over
+// the first hour, against the first configured horizon.
+//
+// day is YYYY-MM-DD; empty picks the median-size day that has a predecessor.
+func RunWarmup(sym, day string, tol float64) {
+	fmt.Println(">>> MODEL WARMUP: COLD vs CARRIED STATE <<<")
+
+	var tasks []ofiTask
+	for t := range discoverTasks(sym) {
+		tasks = append(tasks, t)
+	}
+	if len(tasks) < 2 {
+		fmt.Printf("[%s] need at least two days of data\n", sym)
+		return
+	}
+	sort.Slice(tasks, func(i, j int) bool { return taskDate(tasks[i]).Before(taskDate(tasks[j])) })
+
+	// Only days whose calendar predecessor is present can carry state.
+	var cands []int
+	for i := 1; i < len(tasks); i++ {
+		if taskDate(tasks[i]).Sub(taskDate(tasks[i-1])) == 24*time.Hour {
+			cands = append(cands, i)
+		}
+	}
+	pick := -1
+	if day != "" {
+		for _, i := range cands {
+			if taskDate(tasks[i]).Format("2006-01-02") == day {
+				pick = i
+			}
+		}
+	} else if len(cands) > 0 {
+		sort.Slice(cands, func(a, b int) bool { return tasks[cands[a]].Size < tasks[cands[b]].Size })
+		pick = cands[len(cands)/2]
+	}
+	if pick < 0 {
+		fmt.Printf("[%s] no usable day (requested %q); the day and its predecessor must both exist\n", sym, day)
+		return
+	}
+
+	prev, cur := &DayColumns{}, &DayColumns{}
+	var buf []byte
+	for _, p := range []struct {
+		t    ofiTask
+		cols *DayColumns
+	}{{tasks[pick-1], prev}, {tasks[pick], cur}} {
+		if !LoadGNCFile(SymbolRoot(sym), sym, p.t, &buf) {
+			fmt.Printf("[%s] could not load %s\n", sym, taskDate(p.t).Format("2006-01-02"))
+			return
+		}
+		if _, err := InflateGNC(buf, p.cols); err != nil {
+			fmt.Printf("[%s] could not decode %s: %v\n", sym, taskDate(p.t).Format("2006-01-02"), err)
+			return
+		}
+	}
+	if hasDollarHorizon(Horizons) {
+		cur.FillCumNotional()
+	}
+
+	cold := GetContinuousModels()
+	carried := GetContinuousModels()
+	for _, m := range cold {
+		m.Reset()
+	}
+	for _, m := range carried {
+		m.Reset()
+	}
+	lastT := streamModels(prev, carried, prev.Times[0], nil)
+
+	coldFeats := make([][]float64, len(cold))
+	carriedFeats := make([][]float64, len(carried))
+	for j := range cold {
+		coldFeats[j] = make([]float64, 0, cur.Count)
+		carriedFeats[j] = make([]float64, 0, cur.Count)
+	}
+	streamModels(cur, cold, cur.Times[0], coldFeats)
+	streamModels(cur, carried, lastT, carriedFeats)
+
+	fmt.Printf("Symbol: %s | Day: %s (%d trades) | Warmup day: %s | Tol: %.3g x std | IC: first hour vs %s\n\n",
+		sym, taskDate(tasks[pick]).Format("2006-01-02"), cur.Count,
+		taskDate(tasks[pick-1]).Format("2006-01-02"), tol, HorizonLabels[0])
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MODEL\tCONVERGE_TRADES\tCONVERGE_MIN\tIC_COLD_1H\tIC_CARRIED_1H\tΔIC")
+	fmt.Fprintln(w, "-----\t---------------\t------------\t----------\t-------------\t---")
+	for j, m := range cold {
+		idx := convergenceIndex(coldFeats[j], carriedFeats[j], tol)
+		convMin := "never"
+		if idx < cur.Count {
+			convMin = fmt.Sprintf("%.1f", float64(cur.Times[idx]-cur.Times[0])/60000)
+		}
+		icCold := firstHourIC(cur, coldFeats[j])
+		icCarried := firstHourIC(cur, carriedFeats[j])
+		fmt.Fprintf(w, "%s\t%d\t%s\t%.4f\t%.4f\t%+.4f\n", m.Name(), idx, convMin, icCold, icCarried, icCold-icCarried)
+	}
+	w.Flush()
+}
+
+func taskDate(t ofiTask) time.Time {
+	return time.Date(t.Year, time.Month(t.Month), t.Day, 0, 0, 0, 0, time.UTC)
+}
+
+// streamModels feeds every trade of cols to models (no Reset), starting the
+// dt clock at lastT, and optionally records each model's output per trade.
+// It returns the last trade time so another day can continue the clock.
+func streamModels(cols *DayColumns, models []ContinuousModel, lastT int64, out [][]float64) int64 {
+	sided := sidedModels(models)
+	for i := 0; i < cols.Count; i++ {
+		t := cols.Times[i]
+		dt := float64(t-lastT) / 1000.0
+		if dt < 0 {
+			dt = 0
+		}
+		lastT = t
+		for _, s := range sided {
+			s.SetSide(cols.Signs[i])
+		}
+		for j, m := range models {
+			v := m.Update(dt, cols.Prices[i], cols.Qtys[i])
+			if out != nil {
+				out[j] = append(out[j], v)
+			}
+		}
+	}
+	return lastT
+}
+
+// convergenceIndex returns the first trade from which |a-b| stays within
+// tol * std(b) until the end of the day (len(a) if it never settles).
+func convergenceIndex(a, b []float64, tol float64) int {
+	var mean, m2 float64
+	for _, x := range b {
+		mean += x
+	}
+	mean /= float64(len(b))
+	for _, x := range b {
+		m2 += (x - mean) * (x - mean)
+	}
+	band := tol * math.Sqrt(m2/float64(len(b)))
+
+	last := -1
+	for i := range a {
+		if math.Abs(a[i]-b[i]) > band {
+			last = i
+		}
+	}
+	return last + 1
+}
+
+// firstHourIC samples feats every SamplingRateSec during the first hour of
+// the day and correlates them with the first horizon's forward return.
+func firstHourIC(cols *DayColumns, feats []float64) float64 {
+	end := cols.Times[0] + 3600*1000
+	next := cols.Times[0] + SamplingRateSec*1000
+	var xs, ys []float64
+	for i := 0; i < cols.Count && cols.Times[i] < end; i++ {
+		if cols.Times[i] < next {
+			continue
+		}
+		for next <= cols.Times[i] {
+			next += SamplingRateSec * 1000
+		}
+		exit := Horizons[0].ExitIndex(cols, i)
+		if exit < 0 {
+			continue
+		}
+		r, ok := forwardReturn(cols, i, exit)
+		if !ok {
+			continue
+		}
+		xs = append(xs, feats[i])
+		ys = append(ys, r)
+	}
+	return Pearson(xs, ys)
+}